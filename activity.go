@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// ErrShutdown will be returned for new operations of a [sqlite.DB], after its shutdown started.
+var ErrShutdown = errors.New("database is shutting down")
+
+type shutdownOpKey struct{}
+
+// withShutdownOp marks ctx for the operations of the shutdown itself, which are still accepted.
+func withShutdownOp(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shutdownOpKey{}, true)
+}
+
+// activity tracks the active operations and transactions of the connections of a [sqlite.DB].
+//
+// An operation is active while the driver executes it, a query until its rows are closed
+// and a transaction until its commit or rollback.
+type activity struct {
+	mu       sync.Mutex
+	active   int
+	stopping bool
+	idle     chan struct{} // Closed by signalIdle, if there is no active operation after stop
+	drained  bool
+}
+
+func newActivity() *activity {
+	return &activity{idle: make(chan struct{})}
+}
+
+// begin starts an operation, it fails with [sqlite.ErrShutdown] after stop.
+func (a *activity) begin(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopping && ctx.Value(shutdownOpKey{}) == nil {
+		return ErrShutdown
+	}
+	a.active++
+	return nil
+}
+
+func (a *activity) end() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.active--
+	a.signalIdle()
+}
+
+// stop rejects all new operations.
+func (a *activity) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopping {
+		return
+	}
+	a.stopping = true
+	a.signalIdle()
+}
+
+// signalIdle closes idle once, if there is no active operation after stop. a.mu must be held.
+func (a *activity) signalIdle() {
+	if a.stopping && a.active == 0 && !a.drained {
+		a.drained = true
+		close(a.idle)
+	}
+}
+
+// wait waits until all active operations ended after stop, or ctx is done.
+func (a *activity) wait(ctx context.Context) error {
+	select {
+	case <-a.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// activeTx ends the tracked transaction of conn on commit or rollback.
+type activeTx struct {
+	driver.Tx
+	conn *conn
+	done func()
+}
+
+func (t *activeTx) Commit() error {
+	defer t.end()
+	return t.Tx.Commit()
+}
+
+func (t *activeTx) Rollback() error {
+	defer t.end()
+	return t.Tx.Rollback()
+}
+
+func (t *activeTx) end() {
+	t.conn.inTx = false
+	t.done()
+}
+
+// activeRows ends the tracked query on close and forwards all optional interfaces of the rows used by [database/sql].
+type activeRows struct {
+	driver.Rows
+	done func()
+	once sync.Once
+}
+
+func (r *activeRows) Close() error {
+	defer r.once.Do(r.done)
+	return r.Rows.Close()
+}
+
+func (r *activeRows) HasNextResultSet() bool {
+	if next, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return next.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *activeRows) NextResultSet() error {
+	if next, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return next.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *activeRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *activeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *activeRows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *activeRows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *activeRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
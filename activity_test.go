@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_activity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a := newActivity()
+	if err := a.begin(ctx); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	a.stop()
+	if err := a.begin(ctx); !errors.Is(err, ErrShutdown) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrShutdown, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := a.wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect error to be '%s', got '%s'", context.Canceled, err)
+	}
+
+	a.end()
+	if err := a.wait(ctx); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if err := a.begin(withShutdownOp(ctx)); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	a.end()
+	a.stop()
+	if err := a.wait(ctx); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+}
@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"reflect"
+	"sync"
+)
+
+// DriverAdapter connects a [database/sql] driver for SQLite to this package.
+//
+// Adapters for [sqlite.DriverMattn], [sqlite.DriverModernc] and [sqlite.DriverNcruces] are built in,
+// other drivers can be added per [sqlite.RegisterDriverAdapter].
+//
+// "zombiezen.com/go/sqlite" and "crawshaw.io/sqlite" have their own API and don't implement a [database/sql] driver,
+// so they can't be used with this package.
+type DriverAdapter interface {
+	// Driver returns the [sqlite.Driver], which should be the import path of the driver package.
+	// It is used to tell drivers apart, which are registered with the same name.
+	Driver() Driver
+
+	// DriverName returns the name the driver registers for [sql.Open].
+	DriverName() string
+
+	// BuildDSN returns the DSN for the path and pragmas of config.
+	BuildDSN(config *Config) string
+
+	// ConnPragmas returns the pragma statements of config, which can't be set per DSN.
+	// They are executed on every new connection.
+	ConnPragmas(config *Config) []string
+
+	// ErrorCode returns the result code of err, if err is an error of the driver.
+	// err is a single error of the chain, the chain is unwrapped by [sqlite.ErrorCode].
+	// Only the error types of the driver should be recognized, not every error with a similar method.
+	ErrorCode(err error) (Code, bool)
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   []DriverAdapter
+)
+
+func init() {
+	RegisterDriverAdapter(mattnAdapter{})
+	RegisterDriverAdapter(moderncAdapter{})
+	RegisterDriverAdapter(ncrucesAdapter{})
+}
+
+// RegisterDriverAdapter will register adapter for its [sqlite.Driver], so it can be used per [sqlite.WithDriver].
+//
+// A registered adapter for the same [sqlite.Driver] is replaced.
+// Registered drivers are recognized by the package path of their type, see [sqlite.DetectedDrivers].
+func RegisterDriverAdapter(adapter DriverAdapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+
+	for i, a := range adapters {
+		if a.Driver() == adapter.Driver() {
+			adapters[i] = adapter
+			return
+		}
+	}
+	adapters = append(adapters, adapter)
+}
+
+func registeredAdapters() []DriverAdapter {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+
+	return append([]DriverAdapter(nil), adapters...)
+}
+
+func lookupDriverAdapter(driver Driver) (DriverAdapter, bool) {
+	for _, a := range registeredAdapters() {
+		if a.Driver() == driver {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// mattnAdapter is the [sqlite.DriverAdapter] for "github.com/mattn/go-sqlite3".
+type mattnAdapter struct{}
+
+func (mattnAdapter) Driver() Driver {
+	return DriverMattn
+}
+
+func (mattnAdapter) DriverName() string {
+	return DriverNameMattn
+}
+
+func (mattnAdapter) BuildDSN(config *Config) string {
+	return buildMattnDSN(config)
+}
+
+func (mattnAdapter) ConnPragmas(config *Config) []string {
+	return buildMattnConnPragmas(config)
+}
+
+// ErrorCode reads the fields of sqlite3.Error.
+func (mattnAdapter) ErrorCode(err error) (Code, bool) {
+	if !isDriverError(err, DriverMattn, "Error") {
+		return CodeOK, false
+	}
+	return fieldCode(reflect.Indirect(reflect.ValueOf(err)))
+}
+
+// moderncAdapter is the [sqlite.DriverAdapter] for "modernc.org/sqlite".
+type moderncAdapter struct{}
+
+func (moderncAdapter) Driver() Driver {
+	return DriverModernc
+}
+
+func (moderncAdapter) DriverName() string {
+	return DriverNameModernc
+}
+
+func (moderncAdapter) BuildDSN(config *Config) string {
+	return buildModerncDSN(config)
+}
+
+func (moderncAdapter) ConnPragmas(*Config) []string {
+	return nil
+}
+
+// ErrorCode calls the method Code() int of *sqlite.Error.
+func (moderncAdapter) ErrorCode(err error) (Code, bool) {
+	if !isDriverError(err, DriverModernc, "Error") {
+		return CodeOK, false
+	}
+	return methodCode(err)
+}
+
+// ncrucesAdapter is the [sqlite.DriverAdapter] for "github.com/ncruces/go-sqlite3".
+//
+// The driver uses the same `_pragma` parameters as "modernc.org/sqlite" and executes them in order,
+// but only reads the parameters of a `file:` URI.
+type ncrucesAdapter struct{}
+
+var _ MemoryAdapter = ncrucesAdapter{}
+
+func (ncrucesAdapter) Driver() Driver {
+	return DriverNcruces
+}
+
+func (ncrucesAdapter) DriverName() string {
+	return DriverNameNcruces
+}
+
+// BuildDSN always builds a `file:` URI, the driver ignores the parameters of a plain path.
+func (ncrucesAdapter) BuildDSN(config *Config) string {
+	uriConfig := *config
+	uriConfig.Path = fileURI(config.Path)
+	return buildModerncDSN(&uriConfig)
+}
+
+func (ncrucesAdapter) ConnPragmas(*Config) []string {
+	return nil
+}
+
+// MemoryPath uses the VFS "memdb", the driver is built without shared cache.
+func (ncrucesAdapter) MemoryPath(name string) Path {
+	return FilePath("/" + name).VFS("memdb")
+}
+
+// ErrorCode calls the method ExtendedCode() of *sqlite3.Error, which returns a named integer type.
+// The codes sqlite3.ErrorCode and sqlite3.ExtendedErrorCode are errors as well.
+func (ncrucesAdapter) ErrorCode(err error) (Code, bool) {
+	switch {
+	case isDriverError(err, DriverNcruces, "Error"):
+		return extendedCode(err)
+	case isDriverError(err, DriverNcruces, "ErrorCode", "ExtendedErrorCode"):
+		return intCode(reflect.ValueOf(err))
+	}
+	return CodeOK, false
+}
+
+// isDriverError reports whether the type of err, or the type it points to, is one of names
+// declared in the package of driver.
+//
+// The type is matched like the registered drivers in [sqlite.DetectedDrivers], so no driver has to be imported
+// and errors of other packages with the same methods or fields are not mistaken for errors of SQLite.
+func isDriverError(err error, driver Driver, names ...string) bool {
+	t := reflect.TypeOf(err)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.PkgPath() != string(driver) {
+		return false
+	}
+	for _, name := range names {
+		if t.Name() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+type unitTestAdapter struct {
+	driver     Driver
+	driverName string
+}
+
+func (a unitTestAdapter) Driver() Driver {
+	return a.driver
+}
+
+func (a unitTestAdapter) DriverName() string {
+	return a.driverName
+}
+
+func (a unitTestAdapter) BuildDSN(config *Config) string {
+	return encodeDSN(config.Path, url.Values{"unittest": {"1"}})
+}
+
+func (a unitTestAdapter) ConnPragmas(*Config) []string {
+	return []string{"PRAGMA unittest = 1;"}
+}
+
+func (a unitTestAdapter) ErrorCode(err error) (Code, bool) {
+	if errors.Is(err, errUnitTest) {
+		return CodeMisuse, true
+	}
+	return CodeOK, false
+}
+
+// registerUnitTestAdapter registers adapter until the end of the test.
+func registerUnitTestAdapter(t *testing.T, adapter DriverAdapter) {
+	t.Helper()
+
+	RegisterDriverAdapter(adapter)
+	t.Cleanup(func() {
+		adaptersMu.Lock()
+		defer adaptersMu.Unlock()
+
+		for i, a := range adapters {
+			if a.Driver() == adapter.Driver() {
+				adapters = append(adapters[:i], adapters[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+func TestRegisterDriverAdapter(t *testing.T) {
+	registerUnitTestAdapter(t, unitTestAdapter{driver: "example.com/unittest", driverName: "unittest-adapter"})
+
+	config, err := buildConfig(WithDriver("example.com/unittest"))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.DriverName != "unittest-adapter" {
+		t.Errorf("expected '%s', got '%s'", "unittest-adapter", config.DriverName)
+	}
+	if config.DSN != ":memory:?unittest=1" {
+		t.Errorf("expected dsn '%s', got '%s'", ":memory:?unittest=1", config.DSN)
+	}
+
+	pragmas := connPragmas(config)
+	if len(pragmas) != 2 || pragmas[1] != "PRAGMA unittest = 1;" {
+		t.Errorf("expected the pragma of the adapter, got '%v'", pragmas)
+	}
+
+	if got := ErrorCode(errUnitTest); got != CodeMisuse {
+		t.Errorf("expected '%d', got '%d'", CodeMisuse, got)
+	}
+}
+
+func TestRegisterDriverAdapter_Replace(t *testing.T) {
+	registerUnitTestAdapter(t, unitTestAdapter{driver: "example.com/replace", driverName: "first"})
+	registerUnitTestAdapter(t, unitTestAdapter{driver: "example.com/replace", driverName: "second"})
+
+	adapter, ok := lookupDriverAdapter("example.com/replace")
+	if !ok {
+		t.Fatal("expected a registered adapter")
+	}
+	if got := adapter.DriverName(); got != "second" {
+		t.Errorf("expected '%s', got '%s'", "second", got)
+	}
+}
+
+func Test_buildConfig_UnknownDriver(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(WithDriver("example.com/unknown"))
+	if !errors.Is(err, ErrUnknownDriver) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrUnknownDriver, err)
+	}
+}
+
+func Test_recognizeDriver_PackagePath(t *testing.T) {
+	driverName := "unittest-same-name"
+	sql.Register(driverName, unitTestDriver{})
+	t.Cleanup(func() {
+		delete(drivers, driverName)
+	})
+
+	registerUnitTestAdapter(t, unitTestAdapter{driver: "example.com/other", driverName: driverName})
+	registerUnitTestAdapter(t, unitTestAdapter{driver: "github.com/lanz-dev/go-sqlite", driverName: driverName})
+
+	adapter, ok := recognizeDriver(registeredAdapters(), driverName)
+	if !ok {
+		t.Fatal("expected an adapter")
+	}
+	if got := adapter.Driver(); got != "github.com/lanz-dev/go-sqlite" {
+		t.Errorf("expected the adapter matching the package of the driver, got '%s'", got)
+	}
+}
+
+func Test_recognizeDriver_FirstRegisteredName(t *testing.T) {
+	t.Parallel()
+
+	adapter, ok := recognizeDriver(registeredAdapters(), DriverNameMattn)
+	if !ok {
+		t.Fatal("expected an adapter")
+	}
+	if got := adapter.Driver(); got != DriverMattn {
+		t.Errorf("expected '%s', got '%s'", DriverMattn, got)
+	}
+}
+
+func Test_ncrucesAdapter_BuildDSN(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"", "file::memory:?_pragma=busy_timeout(4000)"},
+		{"data.db", "file:data.db?_pragma=busy_timeout(4000)"},
+		{"/data/db", "file:/data/db?_pragma=busy_timeout(4000)"},
+		{"file:data.db?mode=ro", "file:data.db?_pragma=busy_timeout(4000)&mode=ro"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run("With path '"+tc.path+"'", func(t *testing.T) {
+			t.Parallel()
+
+			config, err := buildConfig(
+				WithDriver(DriverNcruces),
+				WithPath(tc.path),
+				WithJournalMode(JournalDefault),
+				WithForeignKeySupport(false),
+				WithSyncMode(SyncDefault),
+			)
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if config.DSN != tc.expected {
+				t.Errorf("expected '%s', got '%s'", tc.expected, config.DSN)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"time"
+)
+
+var errBackupUnsupported = errors.New("online backup is not supported by the driver")
+
+// ErrRestoreUnsupported will be returned by [sqlite.Restore], if the driver doesn't expose the online backup API.
+var ErrRestoreUnsupported = errors.New("online restore is not supported by the driver")
+
+// BackupOptions for [sqlite.Backup].
+type BackupOptions struct {
+	StepPages int                        // Pages copied per step, a value <= 0 copies all pages in a single step
+	Sleep     time.Duration              // Sleep between two steps, so other connections can use the database
+	Progress  func(remaining, total int) // Called after every step with the remaining and total page count
+}
+
+// Backup will create a consistent copy of a live database at destPath.
+//
+// The online backup API of the driver is used:
+//   - "github.com/mattn/go-sqlite3" per SQLiteConn.Backup
+//   - "modernc.org/sqlite" per conn.NewBackup
+//   - "github.com/ncruces/go-sqlite3" per Conn.BackupInit
+//
+// If the driver doesn't expose the online backup API, e.g. because it is wrapped,
+// `VACUUM INTO` is used as fallback. In this case opts are ignored and destPath must not exist.
+//
+// Passing nil as opts copies all pages in a single step.
+//
+// See https://www.sqlite.org/backup.html and https://www.sqlite.org/lang_vacuum.html#vacuuminto.
+func Backup(ctx context.Context, db *sql.DB, destPath string, opts *BackupOptions) error {
+	if opts == nil {
+		opts = &BackupOptions{}
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		backup, err := newDriverBackup(db.Driver(), UnwrapConn(driverConn), destPath)
+		if err != nil {
+			return err
+		}
+		return runBackup(ctx, backup, opts)
+	})
+	if errors.Is(err, errBackupUnsupported) {
+		return vacuumInto(ctx, conn, destPath)
+	}
+	return err
+}
+
+// Restore will replace the content of the live database db with the database at srcPath.
+//
+// The online backup API of the driver is used in the reverse direction of [sqlite.Backup]:
+//   - "github.com/mattn/go-sqlite3" per SQLiteConn.Backup
+//   - "modernc.org/sqlite" per conn.NewRestore
+//   - "github.com/ncruces/go-sqlite3" per Conn.Restore, opts are ignored
+//
+// [sqlite.ErrRestoreUnsupported] is returned, if the driver doesn't expose the online backup API.
+//
+// Passing nil as opts copies all pages in a single step.
+func Restore(ctx context.Context, db *sql.DB, srcPath string, opts *BackupOptions) error {
+	if opts == nil {
+		opts = &BackupOptions{}
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		driverConn = UnwrapConn(driverConn)
+		if restored, err := restoreNcruces(driverConn, srcPath); restored {
+			return err
+		}
+
+		restore, err := newDriverRestore(db.Driver(), driverConn, srcPath)
+		if err != nil {
+			return err
+		}
+		return runBackup(ctx, restore, opts)
+	})
+}
+
+func vacuumInto(ctx context.Context, conn *sql.Conn, destPath string) error {
+	if _, err := conn.ExecContext(ctx, "VACUUM INTO ?;", destPath); err != nil {
+		return err
+	}
+	return nil
+}
+
+func runBackup(ctx context.Context, backup *driverBackup, opts *BackupOptions) (err error) {
+	defer func() {
+		if errFinish := backup.finish(); err == nil {
+			err = errFinish
+		}
+	}()
+
+	pages := opts.StepPages
+	if pages <= 0 {
+		pages = -1
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		done, err := backup.step(pages)
+		if err != nil {
+			return err
+		}
+		if opts.Progress != nil {
+			if remaining, total, ok := backup.progress(); ok {
+				opts.Progress(remaining, total)
+			}
+		}
+		if done {
+			return nil
+		}
+
+		if opts.Sleep > 0 {
+			timer := time.NewTimer(opts.Sleep)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// driverBackup calls the backup object of the driver per reflection,
+// so there is no need to import a specific driver.
+type driverBackup struct {
+	backup reflect.Value
+	// doneOnTrue is true if Step returns true for a finished backup (mattn, ncruces),
+	// otherwise Step returns true if there are remaining pages (modernc).
+	doneOnTrue bool
+	// remoteConn is the connection to the other database, which is closed on finish.
+	remoteConn driver.Conn
+}
+
+func newDriverBackup(drv driver.Driver, srcConn any, destPath string) (*driverBackup, error) {
+	src := reflect.ValueOf(srcConn)
+
+	// "modernc.org/sqlite": func (c *conn) NewBackup(dstUri string) (*Backup, error)
+	if method := src.MethodByName("NewBackup"); method.IsValid() {
+		if !isBackupInit(method.Type(), reflect.TypeOf("")) {
+			return nil, errBackupUnsupported
+		}
+		out := method.Call([]reflect.Value{reflect.ValueOf(destPath)})
+		if err := reflectError(out[1]); err != nil {
+			return nil, err
+		}
+		return buildDriverBackup(out[0], false, nil)
+	}
+
+	// "github.com/ncruces/go-sqlite3": func (src *Conn) BackupInit(srcDB, dstURI string) (*Backup, error)
+	if raw := rawNcrucesConn(src); raw.IsValid() {
+		method := raw.MethodByName("BackupInit")
+		if !method.IsValid() || !isBackupInit(method.Type(), reflect.TypeOf(""), reflect.TypeOf("")) {
+			return nil, errBackupUnsupported
+		}
+		out := method.Call([]reflect.Value{reflect.ValueOf("main"), reflect.ValueOf(destPath)})
+		if err := reflectError(out[1]); err != nil {
+			return nil, err
+		}
+		return buildDriverBackup(out[0], true, nil)
+	}
+
+	// "github.com/mattn/go-sqlite3": func (destConn *SQLiteConn) Backup(dest string, srcConn *SQLiteConn, src string) (*SQLiteBackup, error)
+	if method := src.MethodByName("Backup"); method.IsValid() {
+		if !isBackupInit(method.Type(), reflect.TypeOf(""), src.Type(), reflect.TypeOf("")) {
+			return nil, errBackupUnsupported
+		}
+
+		destConn, err := drv.Open(destPath)
+		if err != nil {
+			return nil, err
+		}
+		dest := reflect.ValueOf(destConn)
+		if dest.Type() != src.Type() {
+			_ = destConn.Close()
+			return nil, errBackupUnsupported
+		}
+
+		out := dest.MethodByName("Backup").Call([]reflect.Value{reflect.ValueOf("main"), src, reflect.ValueOf("main")})
+		if err := reflectError(out[1]); err != nil {
+			_ = destConn.Close()
+			return nil, err
+		}
+		backup, err := buildDriverBackup(out[0], true, destConn)
+		if err != nil {
+			_ = destConn.Close()
+		}
+		return backup, err
+	}
+
+	return nil, errBackupUnsupported
+}
+
+func newDriverRestore(drv driver.Driver, destConn any, srcPath string) (*driverBackup, error) {
+	dest := reflect.ValueOf(destConn)
+
+	// "modernc.org/sqlite": func (c *conn) NewRestore(srcUri string) (*Backup, error)
+	if method := dest.MethodByName("NewRestore"); method.IsValid() {
+		if !isBackupInit(method.Type(), reflect.TypeOf("")) {
+			return nil, ErrRestoreUnsupported
+		}
+		out := method.Call([]reflect.Value{reflect.ValueOf(srcPath)})
+		if err := reflectError(out[1]); err != nil {
+			return nil, err
+		}
+		return buildDriverBackup(out[0], false, nil)
+	}
+
+	// "github.com/mattn/go-sqlite3": func (destConn *SQLiteConn) Backup(dest string, srcConn *SQLiteConn, src string) (*SQLiteBackup, error)
+	if method := dest.MethodByName("Backup"); method.IsValid() {
+		if !isBackupInit(method.Type(), reflect.TypeOf(""), dest.Type(), reflect.TypeOf("")) {
+			return nil, ErrRestoreUnsupported
+		}
+
+		srcConn, err := drv.Open(srcPath)
+		if err != nil {
+			return nil, err
+		}
+		src := reflect.ValueOf(srcConn)
+		if src.Type() != dest.Type() {
+			_ = srcConn.Close()
+			return nil, ErrRestoreUnsupported
+		}
+
+		out := method.Call([]reflect.Value{reflect.ValueOf("main"), src, reflect.ValueOf("main")})
+		if err := reflectError(out[1]); err != nil {
+			_ = srcConn.Close()
+			return nil, err
+		}
+		restore, err := buildDriverBackup(out[0], true, srcConn)
+		if err != nil {
+			_ = srcConn.Close()
+			if errors.Is(err, errBackupUnsupported) {
+				err = ErrRestoreUnsupported
+			}
+		}
+		return restore, err
+	}
+
+	return nil, ErrRestoreUnsupported
+}
+
+// restoreNcruces restores with "github.com/ncruces/go-sqlite3": func (dst *Conn) Restore(dstDB, srcURI string) error
+func restoreNcruces(destConn any, srcPath string) (bool, error) {
+	raw := rawNcrucesConn(reflect.ValueOf(destConn))
+	if !raw.IsValid() {
+		return false, nil
+	}
+	method := raw.MethodByName("Restore")
+	if !method.IsValid() || method.Type().NumIn() != 2 || method.Type().NumOut() != 1 ||
+		method.Type().In(0).Kind() != reflect.String || method.Type().In(1).Kind() != reflect.String ||
+		!isErrorType(method.Type().Out(0)) {
+		return false, nil
+	}
+	out := method.Call([]reflect.Value{reflect.ValueOf("main"), reflect.ValueOf(srcPath)})
+	return true, reflectError(out[0])
+}
+
+// rawNcrucesConn returns the *sqlite3.Conn of a connection of "github.com/ncruces/go-sqlite3" per its method Raw.
+func rawNcrucesConn(conn reflect.Value) reflect.Value {
+	method := conn.MethodByName("Raw")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 ||
+		method.Type().Out(0).Kind() != reflect.Pointer {
+		return reflect.Value{}
+	}
+	raw := method.Call(nil)[0]
+	if raw.IsNil() {
+		return reflect.Value{}
+	}
+	return raw
+}
+
+func isBackupInit(method reflect.Type, in ...reflect.Type) bool {
+	if method.NumIn() != len(in) || method.NumOut() != 2 || !isErrorType(method.Out(1)) {
+		return false
+	}
+	for i, t := range in {
+		if method.In(i) != t {
+			return false
+		}
+	}
+	return true
+}
+
+func buildDriverBackup(backup reflect.Value, doneOnTrue bool, remoteConn driver.Conn) (*driverBackup, error) {
+	step := backup.MethodByName("Step")
+	if !step.IsValid() || step.Type().NumIn() != 1 || step.Type().NumOut() != 2 {
+		return nil, errBackupUnsupported
+	}
+	switch step.Type().In(0).Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+	default:
+		return nil, errBackupUnsupported
+	}
+	if step.Type().Out(0).Kind() != reflect.Bool || !isErrorType(step.Type().Out(1)) {
+		return nil, errBackupUnsupported
+	}
+	if _, ok := backupFinisher(backup); !ok {
+		return nil, errBackupUnsupported
+	}
+
+	return &driverBackup{
+		backup:     backup,
+		doneOnTrue: doneOnTrue,
+		remoteConn: remoteConn,
+	}, nil
+}
+
+func (b *driverBackup) step(pages int) (bool, error) {
+	method := b.backup.MethodByName("Step")
+	arg := reflect.New(method.Type().In(0)).Elem()
+	arg.SetInt(int64(pages))
+
+	out := method.Call([]reflect.Value{arg})
+	if err := reflectError(out[1]); err != nil {
+		return false, err
+	}
+	if b.doneOnTrue {
+		return out[0].Bool(), nil
+	}
+	return !out[0].Bool(), nil
+}
+
+func (b *driverBackup) progress() (remaining, total int, ok bool) {
+	p, ok := b.backup.Interface().(interface {
+		Remaining() int
+		PageCount() int
+	})
+	if !ok {
+		return 0, 0, false
+	}
+	return p.Remaining(), p.PageCount(), true
+}
+
+func (b *driverBackup) finish() error {
+	finish, _ := backupFinisher(b.backup)
+	err := finish()
+	if b.remoteConn != nil {
+		if errClose := b.remoteConn.Close(); err == nil {
+			err = errClose
+		}
+	}
+	return err
+}
+
+// backupFinisher returns Finish (mattn, modernc) or Close (ncruces) of the backup object.
+func backupFinisher(backup reflect.Value) (func() error, bool) {
+	switch b := backup.Interface().(type) {
+	case interface{ Finish() error }:
+		return b.Finish, true
+	case interface{ Close() error }:
+		return b.Close, true
+	}
+	return nil, false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func isErrorType(t reflect.Type) bool {
+	return t == errorType
+}
+
+func reflectError(v reflect.Value) error {
+	if v.IsNil() {
+		return nil
+	}
+	return v.Interface().(error)
+}
@@ -0,0 +1,363 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// backupConnector opens connections shaped like the driver connections of "modernc.org/sqlite" (moderncConn),
+// "github.com/mattn/go-sqlite3" (mattnConn) or "github.com/ncruces/go-sqlite3" (ncrucesConn).
+type backupConnector struct {
+	open func(name string) (driver.Conn, error)
+}
+
+func (c backupConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.open("source")
+}
+
+func (c backupConnector) Driver() driver.Driver {
+	return c
+}
+
+func (c backupConnector) Open(name string) (driver.Conn, error) {
+	return c.open(name)
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+type fakeBackup struct {
+	steps     []int
+	pages     int
+	remaining int
+	finished  bool
+	stepErr   error
+}
+
+func (b *fakeBackup) step(n int) {
+	b.steps = append(b.steps, n)
+	if n < 0 || n > b.remaining {
+		n = b.remaining
+	}
+	b.remaining -= n
+}
+
+func (b *fakeBackup) Remaining() int { return b.remaining }
+func (b *fakeBackup) PageCount() int { return b.pages }
+func (b *fakeBackup) Finish() error {
+	b.finished = true
+	return nil
+}
+
+type moderncBackup struct{ *fakeBackup }
+
+func (b moderncBackup) Step(n int32) (bool, error) {
+	if b.stepErr != nil {
+		return false, b.stepErr
+	}
+	b.step(int(n))
+	return b.remaining > 0, nil
+}
+
+type moderncConn struct {
+	fakeConn
+	backup *fakeBackup
+	dest   *string
+}
+
+func (c *moderncConn) NewBackup(dstURI string) (*moderncBackup, error) {
+	*c.dest = dstURI
+	return &moderncBackup{c.backup}, nil
+}
+
+func (c *moderncConn) NewRestore(srcURI string) (*moderncBackup, error) {
+	*c.dest = srcURI
+	return &moderncBackup{c.backup}, nil
+}
+
+type mattnBackup struct{ *fakeBackup }
+
+func (b mattnBackup) Step(n int) (bool, error) {
+	b.step(n)
+	return b.remaining == 0, nil
+}
+
+type mattnConn struct {
+	fakeConn
+	name   string
+	backup *fakeBackup
+	closed *bool
+}
+
+func (c *mattnConn) Backup(dest string, srcConn *mattnConn, src string) (*mattnBackup, error) {
+	if dest != "main" || src != "main" || srcConn.backup == nil {
+		return nil, errors.New("unexpected backup call")
+	}
+	return &mattnBackup{srcConn.backup}, nil
+}
+
+func (c *mattnConn) Close() error {
+	if c.closed != nil {
+		*c.closed = true
+	}
+	return nil
+}
+
+type ncrucesBackup struct{ *fakeBackup }
+
+func (b ncrucesBackup) Step(n int) (bool, error) {
+	b.step(n)
+	return b.remaining == 0, nil
+}
+
+func (b ncrucesBackup) Close() error {
+	return b.Finish()
+}
+
+type ncrucesRaw struct {
+	backup *fakeBackup
+	uri    string
+}
+
+func (r *ncrucesRaw) BackupInit(srcDB, dstURI string) (*ncrucesBackup, error) {
+	if srcDB != "main" {
+		return nil, errors.New("unexpected backup call")
+	}
+	r.uri = dstURI
+	return &ncrucesBackup{r.backup}, nil
+}
+
+func (r *ncrucesRaw) Restore(dstDB, srcURI string) error {
+	if dstDB != "main" {
+		return errors.New("unexpected restore call")
+	}
+	r.uri = srcURI
+	return nil
+}
+
+type ncrucesConn struct {
+	fakeConn
+	raw *ncrucesRaw
+}
+
+func (c *ncrucesConn) Raw() *ncrucesRaw {
+	return c.raw
+}
+
+func TestBackup_Modernc(t *testing.T) {
+	t.Parallel()
+
+	backup := &fakeBackup{pages: 10, remaining: 10}
+	var dest string
+	db := sql.OpenDB(backupConnector{open: func(string) (driver.Conn, error) {
+		return &moderncConn{backup: backup, dest: &dest}, nil
+	}})
+	defer db.Close()
+
+	var progress []int
+	err := sqlite.Backup(context.Background(), db, "file:backup.db", &sqlite.BackupOptions{
+		StepPages: 4,
+		Progress: func(remaining, total int) {
+			progress = append(progress, remaining)
+			if total != 10 {
+				t.Errorf("expected '%d', got '%d'", 10, total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if dest != "file:backup.db" {
+		t.Errorf("expected '%s', got '%s'", "file:backup.db", dest)
+	}
+	if len(backup.steps) != 3 || backup.steps[0] != 4 {
+		t.Errorf("expected 3 steps with 4 pages, got '%v'", backup.steps)
+	}
+	if len(progress) != 3 || progress[2] != 0 {
+		t.Errorf("unexpected progress '%v'", progress)
+	}
+	if !backup.finished {
+		t.Error("expected backup to be finished")
+	}
+}
+
+func TestBackup_ModerncWithStepError(t *testing.T) {
+	t.Parallel()
+
+	errUnitTest := errors.New("unittest")
+	backup := &fakeBackup{pages: 10, remaining: 10, stepErr: errUnitTest}
+	var dest string
+	db := sql.OpenDB(backupConnector{open: func(string) (driver.Conn, error) {
+		return &moderncConn{backup: backup, dest: &dest}, nil
+	}})
+	defer db.Close()
+
+	err := sqlite.Backup(context.Background(), db, "file:backup.db", nil)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if !backup.finished {
+		t.Error("expected backup to be finished")
+	}
+}
+
+func TestBackup_Mattn(t *testing.T) {
+	t.Parallel()
+
+	backup := &fakeBackup{pages: 10, remaining: 10}
+	var destName string
+	var destClosed bool
+	db := sql.OpenDB(backupConnector{open: func(name string) (driver.Conn, error) {
+		if name == "source" {
+			return &mattnConn{name: name, backup: backup}, nil
+		}
+		destName = name
+		return &mattnConn{name: name, closed: &destClosed}, nil
+	}})
+	defer db.Close()
+
+	err := sqlite.Backup(context.Background(), db, "file:backup.db", nil)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if destName != "file:backup.db" {
+		t.Errorf("expected '%s', got '%s'", "file:backup.db", destName)
+	}
+	if len(backup.steps) != 1 || backup.steps[0] != -1 {
+		t.Errorf("expected a single step with all pages, got '%v'", backup.steps)
+	}
+	if !backup.finished {
+		t.Error("expected backup to be finished")
+	}
+	if !destClosed {
+		t.Error("expected destination connection to be closed")
+	}
+}
+
+func TestBackup_VacuumIntoFallback(t *testing.T) {
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("VACUUM INTO").
+		WithArgs("file:backup.db").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := sqlite.Backup(context.Background(), db, "file:backup.db", nil); err != nil {
+		t.Fatalf("did not expected error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBackup_Ncruces(t *testing.T) {
+	t.Parallel()
+
+	raw := &ncrucesRaw{backup: &fakeBackup{pages: 10, remaining: 10}}
+	db := sql.OpenDB(backupConnector{open: func(string) (driver.Conn, error) {
+		return &ncrucesConn{raw: raw}, nil
+	}})
+	defer db.Close()
+
+	err := sqlite.Backup(context.Background(), db, "file:backup.db", &sqlite.BackupOptions{StepPages: 5})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if raw.uri != "file:backup.db" {
+		t.Errorf("expected '%s', got '%s'", "file:backup.db", raw.uri)
+	}
+	if len(raw.backup.steps) != 2 {
+		t.Errorf("expected 2 steps, got '%v'", raw.backup.steps)
+	}
+	if !raw.backup.finished {
+		t.Error("expected backup to be finished")
+	}
+}
+
+func TestRestore_Modernc(t *testing.T) {
+	t.Parallel()
+
+	backup := &fakeBackup{pages: 10, remaining: 10}
+	var src string
+	db := sql.OpenDB(backupConnector{open: func(string) (driver.Conn, error) {
+		return &moderncConn{backup: backup, dest: &src}, nil
+	}})
+	defer db.Close()
+
+	if err := sqlite.Restore(context.Background(), db, "file:snapshot.db", nil); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if src != "file:snapshot.db" {
+		t.Errorf("expected '%s', got '%s'", "file:snapshot.db", src)
+	}
+	if !backup.finished {
+		t.Error("expected restore to be finished")
+	}
+}
+
+func TestRestore_Mattn(t *testing.T) {
+	t.Parallel()
+
+	backup := &fakeBackup{pages: 10, remaining: 10}
+	var srcName string
+	var srcClosed bool
+	db := sql.OpenDB(backupConnector{open: func(name string) (driver.Conn, error) {
+		if name == "source" {
+			return &mattnConn{name: name}, nil
+		}
+		srcName = name
+		return &mattnConn{name: name, backup: backup, closed: &srcClosed}, nil
+	}})
+	defer db.Close()
+
+	err := sqlite.Restore(context.Background(), db, "file:snapshot.db", &sqlite.BackupOptions{StepPages: 3})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if srcName != "file:snapshot.db" {
+		t.Errorf("expected '%s', got '%s'", "file:snapshot.db", srcName)
+	}
+	if len(backup.steps) != 4 {
+		t.Errorf("expected 4 steps, got '%v'", backup.steps)
+	}
+	if !srcClosed {
+		t.Error("expected source connection to be closed")
+	}
+}
+
+func TestRestore_Ncruces(t *testing.T) {
+	t.Parallel()
+
+	raw := &ncrucesRaw{}
+	db := sql.OpenDB(backupConnector{open: func(string) (driver.Conn, error) {
+		return &ncrucesConn{raw: raw}, nil
+	}})
+	defer db.Close()
+
+	if err := sqlite.Restore(context.Background(), db, "file:snapshot.db", nil); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if raw.uri != "file:snapshot.db" {
+		t.Errorf("expected '%s', got '%s'", "file:snapshot.db", raw.uri)
+	}
+}
+
+func TestRestore_Unsupported(t *testing.T) {
+	db, _ := buildMockDB(t)
+	defer db.Close()
+
+	err := sqlite.Restore(context.Background(), db, "file:snapshot.db", nil)
+	if !errors.Is(err, sqlite.ErrRestoreUnsupported) {
+		t.Fatalf("expect error to be '%s', got '%s'", sqlite.ErrRestoreUnsupported, err)
+	}
+}
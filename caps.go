@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingCapability will be returned by [sqlite.Connect], if the linked SQLite library misses a capability
+// required per [sqlite.WithRequire].
+var ErrMissingCapability = errors.New("missing capability")
+
+// Caps are the capabilities of the linked SQLite library, see [sqlite.Capabilities].
+//
+// They depend on the [sqlite.Driver], e.g. "modernc.org/sqlite" has FTS5 and the math functions,
+// "github.com/mattn/go-sqlite3" only with the build tags "sqlite_fts5" and "sqlite_math_functions".
+type Caps struct {
+	Version        string   // https://www.sqlite.org/lang_corefunc.html#sqlite_version, like "3.45.1"
+	CompileOptions []string // https://www.sqlite.org/pragma.html#pragma_compile_options, like "ENABLE_FTS5"
+	Modules        []string // https://www.sqlite.org/pragma.html#pragma_module_list, like "fts5"
+
+	features map[string]bool // Probed features by upper case name
+}
+
+// versionFeatures are the features of [sqlite.Caps.Has], which are available since a version of SQLite.
+var versionFeatures = map[string]string{
+	"RETURNING": "3.35.0", // https://www.sqlite.org/lang_returning.html
+	"STRICT":    "3.37.0", // https://www.sqlite.org/stricttables.html
+	"UPSERT":    "3.24.0", // https://www.sqlite.org/lang_upsert.html
+}
+
+// probeFeatures are the features of [sqlite.Caps.Has], which are available if the query succeeds.
+var probeFeatures = map[string]string{
+	"JSON1": "SELECT json('{}');",
+	"MATH":  "SELECT sqrt(4);",
+}
+
+// Capabilities returns the capabilities of the SQLite library linked by the driver of db.
+//
+// The version and compile options are read, the features of [sqlite.Caps.Has] are probed.
+// Older versions of SQLite and builds without `PRAGMA module_list` have no modules,
+// so FTS5 and RTREE are only detected per their compile options.
+func Capabilities(ctx context.Context, db *sql.DB) (*Caps, error) {
+	caps := &Caps{features: map[string]bool{}}
+
+	if err := db.QueryRowContext(ctx, "SELECT sqlite_version();").Scan(&caps.Version); err != nil {
+		return nil, fmt.Errorf("sqlite_version: %w", err)
+	}
+	options, err := queryStrings(ctx, db, "PRAGMA compile_options;")
+	if err != nil {
+		return nil, fmt.Errorf("pragma 'compile_options': %w", err)
+	}
+	caps.CompileOptions = options
+
+	modules, err := queryStrings(ctx, db, "SELECT name FROM pragma_module_list;")
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err == nil {
+		caps.Modules = modules
+	}
+	for _, module := range caps.Modules {
+		caps.features[strings.ToUpper(module)] = true
+	}
+
+	for feature, query := range probeFeatures {
+		_, err := db.ExecContext(ctx, query)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		caps.features[feature] = err == nil
+	}
+	for feature, version := range versionFeatures {
+		caps.features[feature] = caps.AtLeast(version)
+	}
+
+	return caps, nil
+}
+
+// AtLeast reports whether the version of SQLite is at least version, like "3.35.0".
+//
+// Missing parts of version are 0, it is false for an invalid version.
+func (c *Caps) AtLeast(version string) bool {
+	want, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	got, ok := parseVersion(c.Version)
+	if !ok {
+		return false
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			return got[i] > want[i]
+		}
+	}
+	return true
+}
+
+// Has reports whether SQLite has the feature name, which is case-insensitive.
+//
+// The probed features are "JSON1", "MATH" (the math functions), "RETURNING", "STRICT" (tables), "UPSERT"
+// and the names of all [sqlite.Caps.Modules], like "FTS5" and "RTREE".
+// All other names are looked up in the [sqlite.Caps.CompileOptions] with and without the prefix "ENABLE_",
+// e.g. "FTS5", "ENABLE_FTS5" or "THREADSAFE", for "THREADSAFE=1". The prefix "SQLITE_" is ignored.
+func (c *Caps) Has(name string) bool {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "SQLITE_"), "ENABLE_")
+	if c.features[name] {
+		return true
+	}
+	for _, option := range c.CompileOptions {
+		option, _, _ = strings.Cut(option, "=")
+		if option == name || option == "ENABLE_"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// require returns an error for each requirement missed by c, a requirement is a version or a feature of
+// [sqlite.Caps.Has].
+func (c *Caps) require(requirements []string) error {
+	var errs []error
+	for _, req := range requirements {
+		if isVersion(req) && c.AtLeast(req) || !isVersion(req) && c.Has(req) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("required '%s' with SQLite %s, %w", req, c.Version, ErrMissingCapability))
+	}
+	return errors.Join(errs...)
+}
+
+// requireCapabilities returns an error, if SQLite of db misses one of the requirements of [sqlite.WithRequire].
+func requireCapabilities(ctx context.Context, db *sql.DB, requirements []string) error {
+	caps, err := Capabilities(ctx, db)
+	if err != nil {
+		return err
+	}
+	return caps.require(requirements)
+}
+
+// checkRequirements accepts features and versions like "3.35.0".
+func checkRequirements(requirements []string) error {
+	for _, req := range requirements {
+		if strings.TrimSpace(req) == "" {
+			return fmt.Errorf("given empty requirement, %w", ErrInvalidOption)
+		}
+		if _, ok := parseVersion(req); isVersion(req) && !ok {
+			return fmt.Errorf("given version '%s', %w", req, ErrInvalidOption)
+		}
+	}
+	return nil
+}
+
+// isVersion reports whether the requirement s is a version, which starts with a digit.
+func isVersion(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// parseVersion parses a version like "3.35.0" or "3.35", up to three parts.
+func parseVersion(s string) ([3]int, bool) {
+	var version [3]int
+	parts := strings.Split(s, ".")
+	if len(parts) > len(version) {
+		return version, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
+// queryStrings returns the first column of all rows of query.
+func queryStrings(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
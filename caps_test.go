@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCaps_AtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"3.35.0", true},
+		{"3.35", true},
+		{"3", true},
+		{"3.35.5", true},
+		{"3.35.6", false},
+		{"3.36.0", false},
+		{"4.0.0", false},
+		{"2.99.99", true},
+		{"3.x", false},
+		{"3.35.0.1", false},
+		{"", false},
+	}
+	caps := &Caps{Version: "3.35.5"}
+	for _, tc := range tests {
+		tc := tc
+		t.Run("With version '"+tc.version+"'", func(t *testing.T) {
+			t.Parallel()
+
+			if got := caps.AtLeast(tc.version); got != tc.want {
+				t.Errorf("expected '%v', got '%v'", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCaps_Has(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"FTS5", true},
+		{"fts5", true},
+		{"SQLITE_ENABLE_FTS5", true},
+		{"ENABLE_RTREE", true},
+		{"RTREE", true},
+		{"THREADSAFE", true},
+		{"JSON1", true},
+		{"MATH", false},
+		{"GEOPOLY", false},
+		{"ENABLE", false},
+	}
+	caps := &Caps{
+		Version:        "3.45.1",
+		CompileOptions: []string{"ENABLE_RTREE", "THREADSAFE=1"},
+		Modules:        []string{"fts5"},
+		features:       map[string]bool{"FTS5": true, "JSON1": true, "MATH": false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := caps.Has(tc.name); got != tc.want {
+				t.Errorf("expected '%v', got '%v'", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCaps_require(t *testing.T) {
+	t.Parallel()
+
+	caps := &Caps{Version: "3.31.1", CompileOptions: []string{"ENABLE_FTS5"}}
+	if err := caps.require([]string{"3.31", "FTS5"}); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	err := caps.require([]string{"3.35.0", "FTS5", "MATH"})
+	if !errors.Is(err, ErrMissingCapability) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrMissingCapability, err)
+	}
+	if got := len(err.(interface{ Unwrap() []error }).Unwrap()); got != 2 {
+		t.Errorf("expected '%d', got '%d'", 2, got)
+	}
+}
+
+func Test_checkRequirements(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements []string
+		wantErr      error
+	}{
+		{"Valid", []string{"3.35.0", "FTS5"}, nil},
+		{"None", nil, nil},
+		{"Empty", []string{""}, ErrInvalidOption},
+		{"InvalidVersion", []string{"3.35.x"}, ErrInvalidOption},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkRequirements(tc.requirements)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCheckpointMode will be returned by [sqlite.Checkpoint] for an unknown [sqlite.CheckpointMode].
+var ErrInvalidCheckpointMode = errors.New("invalid checkpoint mode")
+
+// CheckpointMode is the mode of a WAL checkpoint.
+//
+// See https://www.sqlite.org/pragma.html#pragma_wal_checkpoint.
+type CheckpointMode string
+
+// The different available checkpoint modes.
+//
+// See https://www.sqlite.org/c3ref/wal_checkpoint_v2.html.
+const (
+	// CheckpointPassive checkpoints as many frames as possible without waiting for readers or writers.
+	CheckpointPassive CheckpointMode = "PASSIVE"
+	// CheckpointFull waits for the writers and then for the readers, so all frames are checkpointed.
+	CheckpointFull CheckpointMode = "FULL"
+	// CheckpointRestart works like [sqlite.CheckpointFull] and waits until the next writer can restart the WAL file.
+	CheckpointRestart CheckpointMode = "RESTART"
+	// CheckpointTruncate works like [sqlite.CheckpointRestart] and truncates the WAL file to zero bytes.
+	CheckpointTruncate CheckpointMode = "TRUNCATE"
+)
+
+// CheckpointResult is the result of a WAL checkpoint.
+//
+// See https://www.sqlite.org/pragma.html#pragma_wal_checkpoint.
+type CheckpointResult struct {
+	Busy         bool // The checkpoint was blocked by a reader or writer and couldn't complete
+	LogFrames    int  // Frames in the WAL file, -1 if the database isn't in WAL mode
+	Checkpointed int  // Frames checkpointed into the database, -1 if the database isn't in WAL mode
+}
+
+// Checkpoint will run a WAL checkpoint of db with mode, [sqlite.CheckpointPassive] is used for an empty mode.
+//
+// The modes besides [sqlite.CheckpointPassive] wait per busy handler, see [sqlite.WithBusyTimeout].
+// A blocked checkpoint isn't an error, but returned as [sqlite.CheckpointResult.Busy].
+// The result is recorded for [sqlite.Stats].
+//
+// See https://www.sqlite.org/pragma.html#pragma_wal_checkpoint.
+func Checkpoint(ctx context.Context, db *sql.DB, mode CheckpointMode) (CheckpointResult, error) {
+	switch mode {
+	case "":
+		mode = CheckpointPassive
+	case CheckpointPassive, CheckpointFull, CheckpointRestart, CheckpointTruncate:
+	default:
+		return CheckpointResult{}, fmt.Errorf("given '%s', %w", mode, ErrInvalidCheckpointMode)
+	}
+
+	var (
+		result CheckpointResult
+		busy   int
+	)
+	query := fmt.Sprintf("PRAGMA wal_checkpoint(%s);", mode)
+	if err := db.QueryRowContext(ctx, query).Scan(&busy, &result.LogFrames, &result.Checkpointed); err != nil {
+		return CheckpointResult{}, err
+	}
+	result.Busy = busy != 0
+
+	if m := lookupDBMetrics(db); m != nil {
+		m.recordCheckpoint(result)
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := map[CheckpointMode]string{
+		"":                 `PRAGMA wal_checkpoint\(PASSIVE\);`,
+		CheckpointPassive:  `PRAGMA wal_checkpoint\(PASSIVE\);`,
+		CheckpointFull:     `PRAGMA wal_checkpoint\(FULL\);`,
+		CheckpointRestart:  `PRAGMA wal_checkpoint\(RESTART\);`,
+		CheckpointTruncate: `PRAGMA wal_checkpoint\(TRUNCATE\);`,
+	}
+	for mode, query := range tests {
+		mode, query := mode, query
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+			mock.ExpectQuery(query).
+				WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(1, 8, 6))
+
+			got, err := Checkpoint(context.Background(), db, mode)
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			expected := CheckpointResult{Busy: true, LogFrames: 8, Checkpointed: 6}
+			if got != expected {
+				t.Errorf("expected '%+v', got '%+v'", expected, got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCheckpoint_InvalidMode(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if _, err := Checkpoint(context.Background(), db, "NONE); DROP TABLE t; --"); !errors.Is(err, ErrInvalidCheckpointMode) {
+		t.Errorf("expect error to be '%s', got '%s'", ErrInvalidCheckpointMode, err)
+	}
+}
+
+func TestCheckpoint_WithError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(`PRAGMA wal_checkpoint\(FULL\);`).WillReturnError(errUnitTest)
+
+	if _, err := Checkpoint(context.Background(), db, CheckpointFull); !errors.Is(err, errUnitTest) {
+		t.Errorf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+}
@@ -1,8 +1,12 @@
 package sqlite
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
 )
 
 // AutoVacuumMode for the SQLite connection.
@@ -31,6 +35,16 @@ func (s *AutoVacuumMode) Int() int {
 // See https://www.sqlite.org/pragma.html#pragma_journal_mode.
 type JournalMode string
 
+// LockingMode for the SQLite Connection.
+//
+// See https://www.sqlite.org/pragma.html#pragma_locking_mode.
+type LockingMode string
+
+// SecureDelete mode for the SQLite Connection.
+//
+// See https://www.sqlite.org/pragma.html#pragma_secure_delete.
+type SecureDelete string
+
 // SyncMode for the SQLite Connection.
 //
 // See https://www.sqlite.org/pragma.html#pragma_synchronous.
@@ -51,6 +65,11 @@ func (s *SyncMode) Int() int {
 	return -99
 }
 
+// TempStore for the SQLite Connection.
+//
+// See https://www.sqlite.org/pragma.html#pragma_temp_store.
+type TempStore string
+
 // The different available AutoVaccum modes for SQLite.
 //
 // See https://www.sqlite.org/pragma.html#pragma_auto_vacuum.
@@ -65,12 +84,17 @@ func (s *SyncMode) Int() int {
 const (
 	DriverMattn   Driver = "github.com/mattn/go-sqlite3"
 	DriverModernc Driver = "modernc.org/sqlite"
+	DriverNcruces Driver = "github.com/ncruces/go-sqlite3"
 )
 
 // These are the default [sql.Open] driverNames for the SQLite drivers.
+//
+// DriverNameMattn and DriverNameNcruces are both "sqlite3", so only one of these drivers can be imported,
+// importing both panics on the duplicate [sql.Register].
 const (
 	DriverNameMattn   = "sqlite3"
 	DriverNameModernc = "sqlite"
+	DriverNameNcruces = "sqlite3"
 )
 
 // The different available Journal modes for SQLite.
@@ -86,6 +110,25 @@ func (s *SyncMode) Int() int {
 	JournalOff      JournalMode = "OFF"
 )
 
+// The different available Locking modes for SQLite.
+//
+// See https://www.sqlite.org/pragma.html#pragma_locking_mode.
+const (
+	LockingDefault   LockingMode = ""
+	LockingNormal    LockingMode = "NORMAL"
+	LockingExclusive LockingMode = "EXCLUSIVE"
+)
+
+// The different available Secure delete modes for SQLite.
+//
+// See https://www.sqlite.org/pragma.html#pragma_secure_delete.
+const (
+	SecureDeleteDefault SecureDelete = ""
+	SecureDeleteOff     SecureDelete = "OFF"
+	SecureDeleteOn      SecureDelete = "ON"
+	SecureDeleteFast    SecureDelete = "FAST"
+)
+
 // The different available Sync modes for SQLite.
 //
 // See https://www.sqlite.org/pragma.html#pragma_synchronous.
@@ -97,24 +140,68 @@ func (s *SyncMode) Int() int {
 	SyncExtra   SyncMode = "EXTRA"
 )
 
-var regexPath = regexp.MustCompile(`^file:.+\..+$`)
+// The different available Temp store modes for SQLite.
+//
+// See https://www.sqlite.org/pragma.html#pragma_temp_store.
+const (
+	TempStoreDefault TempStore = ""
+	TempStoreFile    TempStore = "FILE"
+	TempStoreMemory  TempStore = "MEMORY"
+)
 
 // Config for the SQLite connection.
+//
+// The tags allow to decode a config file into [sqlite.DefaultConfig], which is used per [sqlite.WithConfig].
+// The modes are decoded case-insensitive and unknown values are rejected.
 type Config struct {
-	DSN             string // DSN string for [sql.Open]
-	Driver          Driver // [sqlite.DriverMattn] or [sqlite.DriverModernc]
-	DriverName      string // DriverName used in [sql.Open]
-	Path            string // Path to the SQLite database
-	LimitConnection bool   // Should we set the default limits?
-
-	AutoVacuumMode    AutoVacuumMode // https://www.sqlite.org/pragma.html#pragma_auto_vacuum
-	BusyTimeout       int            // https://www.sqlite.org/pragma.html#pragma_busy_timeout
-	CaseSensitiveLike bool           // https://www.sqlite.org/pragma.html#pragma_case_sensitive_like
-	DeferForeignKeys  bool           // https://www.sqlite.org/pragma.html#pragma_defer_foreign_keys
-	ForeignKey        bool           // https://www.sqlite.org/pragma.html#pragma_foreign_keys
-	JournalMode       JournalMode    // https://www.sqlite.org/pragma.html#pragma_journal_mode
-	JournalSizeLimit  int            // https://www.sqlite.org/pragma.html#pragma_journal_size_limit
-	SyncMode          SyncMode       // https://www.sqlite.org/pragma.html#pragma_synchronous
+	DSN              string   `json:"-" yaml:"-"`                                                     // DSN string for [sql.Open]
+	Driver           Driver   `json:"driver" yaml:"driver"`                                           // [sqlite.DriverMattn], [sqlite.DriverModernc], [sqlite.DriverNcruces] or a registered [sqlite.DriverAdapter]
+	DriverName       string   `json:"driver_name" yaml:"driver_name"`                                 // DriverName used in [sql.Open]
+	DriverPreference []Driver `json:"driver_preference,omitempty" yaml:"driver_preference,omitempty"` // Drivers preferred on detection, see [sqlite.WithDriverPreference]
+	Path             string   `json:"path" yaml:"path"`                                               // Path to the SQLite database
+	CreateDirs       bool     `json:"create_dirs" yaml:"create_dirs"`                                 // Create the parent directories of Path on connect, see [sqlite.Path.CreateDirs]
+	LimitConnection  bool     `json:"limit_connection" yaml:"limit_connection"`                       // Should we set the default limits?
+	ReadConnections  int      `json:"read_connections" yaml:"read_connections"`                       // Max open connections of the reader pool in [sqlite.ConnectPool]
+	Migrations       fs.FS    `json:"-" yaml:"-"`                                                     // Migrations applied on connect, see package "github.com/lanz-dev/go-sqlite/migrate"
+
+	ConnInit          []ConnInitFunc     `json:"-" yaml:"-"` // Run on every new connection, see [sqlite.WithConnInit]
+	QueryHooks        []Hook             `json:"-" yaml:"-"` // Called for every query, see [sqlite.WithQueryHook]
+	ConnectorWrappers []ConnectorWrapper `json:"-" yaml:"-"` // Wrap the connector of the [sql.DB], see [sqlite.WithConnectorWrapper]
+
+	Maintenance   *MaintenanceConfig `json:"-" yaml:"-"`                           // Maintenance started on connect, see [sqlite.StartMaintenance]
+	StrictPragmas bool               `json:"strict_pragmas" yaml:"strict_pragmas"` // Verify the configured pragmas on connect, see [sqlite.WithStrictPragmas]
+	TxLock        TxMode             `json:"tx_lock" yaml:"tx_lock"`               // Mode to begin all transactions, see [sqlite.WithTxLock]
+	Require       []string           `json:"require" yaml:"require"`               // Capabilities of SQLite required on connect, see [sqlite.WithRequire]
+
+	errs     []error   // Errors of the options, joined by buildConfig
+	activity *activity // Active operations of a [sqlite.DB], set by [sqlite.ConnectPool]
+	preset   Preset    // Preset of [sqlite.WithPreset], which restricts the path
+
+	AutoVacuumMode    AutoVacuumMode `json:"auto_vacuum_mode" yaml:"auto_vacuum_mode"`       // https://www.sqlite.org/pragma.html#pragma_auto_vacuum
+	BusyTimeout       int            `json:"busy_timeout" yaml:"busy_timeout"`               // https://www.sqlite.org/pragma.html#pragma_busy_timeout
+	CacheSize         int            `json:"cache_size" yaml:"cache_size"`                   // https://www.sqlite.org/pragma.html#pragma_cache_size
+	CaseSensitiveLike bool           `json:"case_sensitive_like" yaml:"case_sensitive_like"` // https://www.sqlite.org/pragma.html#pragma_case_sensitive_like
+	CellSizeCheck     bool           `json:"cell_size_check" yaml:"cell_size_check"`         // https://www.sqlite.org/pragma.html#pragma_cell_size_check
+	DeferForeignKeys  bool           `json:"defer_foreign_keys" yaml:"defer_foreign_keys"`   // https://www.sqlite.org/pragma.html#pragma_defer_foreign_keys
+	ForeignKey        bool           `json:"foreign_keys" yaml:"foreign_keys"`               // https://www.sqlite.org/pragma.html#pragma_foreign_keys
+	JournalMode       JournalMode    `json:"journal_mode" yaml:"journal_mode"`               // https://www.sqlite.org/pragma.html#pragma_journal_mode
+	JournalSizeLimit  int            `json:"journal_size_limit" yaml:"journal_size_limit"`   // https://www.sqlite.org/pragma.html#pragma_journal_size_limit
+	LockingMode       LockingMode    `json:"locking_mode" yaml:"locking_mode"`               // https://www.sqlite.org/pragma.html#pragma_locking_mode
+	MmapSize          int64          `json:"mmap_size" yaml:"mmap_size"`                     // https://www.sqlite.org/pragma.html#pragma_mmap_size
+	PageSize          int            `json:"page_size" yaml:"page_size"`                     // https://www.sqlite.org/pragma.html#pragma_page_size
+	QueryOnly         bool           `json:"query_only" yaml:"query_only"`                   // https://www.sqlite.org/pragma.html#pragma_query_only
+	RecursiveTriggers bool           `json:"recursive_triggers" yaml:"recursive_triggers"`   // https://www.sqlite.org/pragma.html#pragma_recursive_triggers
+	SecureDelete      SecureDelete   `json:"secure_delete" yaml:"secure_delete"`             // https://www.sqlite.org/pragma.html#pragma_secure_delete
+	SyncMode          SyncMode       `json:"sync_mode" yaml:"sync_mode"`                     // https://www.sqlite.org/pragma.html#pragma_synchronous
+	TempStore         TempStore      `json:"temp_store" yaml:"temp_store"`                   // https://www.sqlite.org/pragma.html#pragma_temp_store
+	WALAutoCheckpoint int            `json:"wal_autocheckpoint" yaml:"wal_autocheckpoint"`   // https://www.sqlite.org/pragma.html#pragma_wal_autocheckpoint
+}
+
+// DefaultConfig returns the [sqlite.Config] with the defaults of [sqlite.Connect].
+//
+// Decode a config file into it or register its flags per [sqlite.Config.RegisterFlags], then use it per [sqlite.WithConfig].
+func DefaultConfig() Config {
+	return *newConfig()
 }
 
 func newConfig() *Config {
@@ -129,14 +216,25 @@ func newConfig() *Config {
 	}
 }
 
+// validatePath accepts [sqlite.MemoryPath], `file:` URIs and plain file system paths.
+//
+// The query string of path must be valid, because the parameters of the DSN are merged into it.
 func validatePath(path string) error {
-	if path == ":memory" {
-		return nil
+	_, query, _ := strings.Cut(path, "?")
+	if _, err := url.ParseQuery(query); err != nil {
+		return fmt.Errorf("given '%s', %w", path, ErrInvalidPath)
 	}
-	if regexPath.MatchString(path) {
+	if isMemoryPath(path) {
 		return nil
 	}
-	return fmt.Errorf("given '%s', %w", path, ErrInvalidPath)
+	name, err := uriFilePath(path)
+	if err != nil {
+		return err
+	}
+	if name == "" || name == string(filepath.Separator) {
+		return fmt.Errorf("given '%s', %w", path, ErrInvalidPath)
+	}
+	return nil
 }
 
 func buildConfig(opts ...Option) (*Config, error) {
@@ -144,34 +242,33 @@ func buildConfig(opts ...Option) (*Config, error) {
 	for _, opt := range opts {
 		opt(config)
 	}
+	if err := errors.Join(config.errs...); err != nil {
+		return nil, err
+	}
 
-	if config.Path == "" {
-		config.Path = ":memory"
+	if config.Path == "" || config.Path == ":memory" {
+		// ":memory" without the trailing colon was the default path, but is a file for SQLite.
+		config.Path = MemoryPath
 	}
-	if err := validatePath(config.Path); err != nil {
+	config.Path = presetPath(config)
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 
-	if config.Driver == "" {
-		var err error
-		if config.Driver, err = detectDriver(); err != nil {
-			return nil, err
-		}
+	if err := selectDriver(config); err != nil {
+		return nil, err
 	}
 
+	adapter, ok := lookupDriverAdapter(config.Driver)
+	if !ok {
+		return nil, fmt.Errorf("given '%s', %w", config.Driver, ErrUnknownDriver)
+	}
 	if config.DriverName == "" {
-		if config.Driver == DriverModernc {
-			config.DriverName = DriverNameModernc
-		} else if config.Driver == DriverMattn {
-			config.DriverName = DriverNameMattn
-		}
+		config.DriverName = adapter.DriverName()
 	}
+	config.Path = adapterMemoryPath(adapter, config.Path)
 
-	if config.Driver == DriverModernc {
-		config.DSN = buildModerncDSN(config)
-	} else if config.Driver == DriverMattn {
-		config.DSN = buildMattnDSN(config)
-	}
+	config.DSN = adapter.BuildDSN(config)
 
 	return config, nil
 }
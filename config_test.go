@@ -38,7 +38,7 @@ func Test_buildConfig_DefaultPath(t *testing.T) {
 	if err != nil {
 		t.Fatalf("did not expect error '%s'", err)
 	}
-	expected := ":memory"
+	expected := MemoryPath
 	if config.Path != expected {
 		t.Fatalf("expected '%s', got '%s'", expected, config.Path)
 	}
@@ -48,7 +48,7 @@ func Test_buildConfig_InvalidPath(t *testing.T) {
 	t.Parallel()
 
 	_, err := buildConfig(
-		WithPath("invalid"),
+		WithPath("file://host/data.db"),
 		WithDriver(DriverModernc),
 	)
 	if !errors.Is(err, ErrInvalidPath) {
@@ -95,7 +95,7 @@ func Test_buildConfig_DSNModernc(t *testing.T) {
 	if err != nil {
 		t.Fatalf("did not expect error '%s'", err)
 	}
-	expected := ":memory?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	expected := ":memory:?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
 	if config.DSN != expected {
 		t.Fatalf("expected '%s', got '%s'", expected, config.DSN)
 	}
@@ -110,7 +110,7 @@ func Test_buildConfig_DSNMattn(t *testing.T) {
 	if err != nil {
 		t.Fatalf("did not expect error '%s'", err)
 	}
-	expected := ":memory?_timeout=4000&_fk=true&_journal=WAL&_sync=1"
+	expected := ":memory:?_fk=true&_journal=WAL&_sync=1&_timeout=4000"
 	if config.DSN != expected {
 		t.Fatalf("expected '%s', got '%s'", expected, config.DSN)
 	}
@@ -168,17 +168,25 @@ func Test_validatePath(t *testing.T) {
 		path    string
 		wantErr error
 	}{
-		{"invalid:/path", ErrInvalidPath},
-		{"invalid", ErrInvalidPath},
-		{"file", ErrInvalidPath},
 		{"file:", ErrInvalidPath},
 		{"file:/", ErrInvalidPath},
-		{"file:/fsafd", ErrInvalidPath},
-		{"file:/data", ErrInvalidPath},
-		{"file:/data.", ErrInvalidPath},
+		{"file:?mode=ro", ErrInvalidPath},
+		{"file://host/data.db", ErrInvalidPath},
+		{"file:data%zz.db", ErrInvalidPath},
+		{"file:data.db?mode=%zz", ErrInvalidPath},
+		{"file:data.db?mode=ro;cache=shared", ErrInvalidPath},
+		{"file:/data", nil},
 		{"file:/data.db", nil},
 		{"file:data.db", nil},
+		{"file:data.db?mode=ro", nil},
+		{"file:///data.db", nil},
+		{"file://localhost/data.db", nil},
 		{"file:../../../../../../data.db", nil},
+		{"file::memory:", nil},
+		{"file:shared?mode=memory&cache=shared", nil},
+		{"/data/db", nil},
+		{"data.db", nil},
+		{":memory:", nil},
 		{":memory", nil},
 	}
 	for _, tc := range tests {
@@ -0,0 +1,466 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+var (
+	errConnInitReused       = errors.New("connection of the init is already in use")
+	errNamedArgsUnsupported = errors.New("driver does not support the use of named parameters")
+)
+
+// ConnInitFunc initializes a new connection, see [sqlite.WithConnInit].
+type ConnInitFunc func(ctx context.Context, conn *sql.Conn) error
+
+// ConnectorWrapper wraps the [driver.Connector] of a [sql.DB] opened with config, see [sqlite.WithConnectorWrapper].
+type ConnectorWrapper func(config *Config, connector driver.Connector) driver.Connector
+
+type sqlOpenFunc func(driverName, dataSourceName string) (driver.Connector, error)
+
+var (
+	_ driver.Connector = dsnConnector{}
+	_ driver.Connector = &initConnector{}
+	_ io.Closer        = &initConnector{}
+	_ driver.Connector = &singleConnector{}
+
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.Pinger             = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.Validator          = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+
+	_ driver.StmtExecContext   = &stmt{}
+	_ driver.StmtQueryContext  = &stmt{}
+	_ driver.NamedValueChecker = &stmt{}
+
+	_ driver.RowsNextResultSet              = &activeRows{}
+	_ driver.RowsColumnTypeScanType         = &activeRows{}
+	_ driver.RowsColumnTypeDatabaseTypeName = &activeRows{}
+	_ driver.RowsColumnTypeLength           = &activeRows{}
+	_ driver.RowsColumnTypeNullable         = &activeRows{}
+	_ driver.RowsColumnTypePrecisionScale   = &activeRows{}
+)
+
+// openConnector returns a [driver.Connector] for dataSourceName of the registered driver driverName.
+func openConnector(driverName, dataSourceName string) (driver.Connector, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	_ = db.Close()
+
+	if d, ok := drv.(driver.DriverContext); ok {
+		return d.OpenConnector(dataSourceName)
+	}
+	return dsnConnector{driver: drv, dsn: dataSourceName}, nil
+}
+
+// dsnConnector is the [driver.Connector] of drivers without [driver.DriverContext].
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// initConnector runs all init funcs on every new connection of connector.
+//
+// The connections are wrapped in a [sqlite.conn], so [sqlite.WithTx] can begin transactions with its mode
+// and the queries and transactions are passed to hook as documented by [sqlite.Hook].
+// All operations are tracked by activity, if set.
+type initConnector struct {
+	connector driver.Connector
+	init      []ConnInitFunc
+	hook      Hook
+	activity  *activity
+	metrics   *dbMetrics
+	onClose   func()
+}
+
+func (c *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.init) == 0 {
+		return &conn{Conn: dc, hook: c.hook, activity: c.activity}, nil
+	}
+
+	if err := initConn(ctx, c.Driver(), dc, c.init); err != nil {
+		_ = dc.Close()
+		return nil, err
+	}
+	return &conn{Conn: dc, hook: c.hook, activity: c.activity}, nil
+}
+
+// Driver returns the [driver.Driver] of connector wrapped in a [sqlite.initDriver], so [sql.DB.Driver] leads back to c.
+func (c *initConnector) Driver() driver.Driver {
+	return &initDriver{Driver: c.connector.Driver(), connector: c}
+}
+
+// Close will close connector, if it implements [io.Closer]. It's called by [sql.DB.Close].
+func (c *initConnector) Close() error {
+	if c.onClose != nil {
+		c.onClose()
+	}
+	if closer, ok := c.connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// initDriver is the [driver.Driver] of a [sql.DB] opened by this package, which refers to its connector,
+// e.g. for the metrics of [sqlite.Stats].
+type initDriver struct {
+	driver.Driver
+	connector *initConnector
+}
+
+// Unwrap returns the [driver.Driver] of the driver, see [sqlite.UnwrapDriver].
+func (d *initDriver) Unwrap() driver.Driver {
+	return d.Driver
+}
+
+// UnwrapDriver returns the [driver.Driver] of the driver, which [sql.DB.Driver] of a database of this package wraps.
+//
+// Use it for type assertions to the driver, e.g. to *sqlite3.SQLiteDriver of "github.com/mattn/go-sqlite3".
+func UnwrapDriver(drv driver.Driver) driver.Driver {
+	for {
+		d, ok := drv.(interface{ Unwrap() driver.Driver })
+		if !ok {
+			return drv
+		}
+		drv = d.Unwrap()
+	}
+}
+
+// lookupConnector returns nil, if db wasn't opened by this package.
+func lookupConnector(db *sql.DB) *initConnector {
+	if d, ok := db.Driver().(*initDriver); ok {
+		return d.connector
+	}
+	return nil
+}
+
+// initConn runs all init funcs on conn.
+//
+// The init funcs need a [sql.Conn], so conn is wrapped in a temporary [sql.DB] which doesn't close it.
+func initConn(ctx context.Context, drv driver.Driver, conn driver.Conn, init []ConnInitFunc) error {
+	db := sql.OpenDB(&singleConnector{driver: drv, conn: conn})
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	c, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	for _, fn := range init {
+		if err := fn(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execInit returns a [sqlite.ConnInitFunc] executing all queries.
+func execInit(queries []string) ConnInitFunc {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		for _, query := range queries {
+			if _, err := conn.ExecContext(ctx, query); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// singleConnector returns conn once, wrapped in a [sqlite.noCloseConn].
+type singleConnector struct {
+	driver driver.Driver
+	conn   driver.Conn
+	used   bool
+}
+
+func (c *singleConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.used {
+		return nil, errConnInitReused
+	}
+	c.used = true
+	return noCloseConn{&conn{Conn: c.conn}}, nil
+}
+
+func (c *singleConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// noCloseConn hides Close of the wrapped [sqlite.conn].
+type noCloseConn struct {
+	*conn
+}
+
+func (c noCloseConn) Close() error {
+	return nil
+}
+
+// conn wraps the [driver.Conn] of the driver and forwards all optional interfaces used by [database/sql].
+//
+// Transactions are started with the [sqlite.TxMode] of the context, see [sqlite.WithTx].
+// All queries and executions of prepared statements are passed to hook, if set, and all transactions,
+// if it's a [sqlite.TxHook].
+// All queries and transactions are tracked by activity, if set, see [sqlite.DB.ShutdownContext].
+type conn struct {
+	driver.Conn
+	hook     Hook
+	activity *activity
+	inTx     bool // Queries within a transaction are tracked by the transaction
+}
+
+// Unwrap returns the [driver.Conn] of the driver, see [sqlite.UnwrapConn].
+func (c *conn) Unwrap() driver.Conn {
+	return c.Conn
+}
+
+// UnwrapConn returns the [driver.Conn] of the driver, which a connection of a database of this package wraps.
+//
+// Use it within [sql.Conn.Raw] for type assertions to the connection of the driver:
+//
+//	err := conn.Raw(func(driverConn any) error {
+//		sqliteConn, ok := sqlite.UnwrapConn(driverConn).(*sqlite3.SQLiteConn)
+//		...
+//	})
+func UnwrapConn(driverConn any) any {
+	for {
+		c, ok := driverConn.(interface{ Unwrap() driver.Conn })
+		if !ok {
+			return driverConn
+		}
+		driverConn = c.Unwrap()
+	}
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done, err := c.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return runHook(ctx, c.hook, query, args, func(ctx context.Context) (driver.Result, error) {
+		return execer.ExecContext(ctx, query, args)
+	})
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done, err := c.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := runHook(ctx, c.hook, query, args, func(ctx context.Context) (driver.Rows, error) {
+		return queryer.QueryContext(ctx, query, args)
+	})
+	return c.trackRows(rows, err, done)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	done, err := c.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	var ds driver.Stmt
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		ds, err = preparer.PrepareContext(ctx, query)
+	} else {
+		ds, err = c.Conn.Prepare(query)
+	}
+	if err != nil || (c.hook == nil && c.activity == nil) {
+		return ds, err
+	}
+	return &stmt{Stmt: ds, conn: c, query: query}, nil
+}
+
+// BeginTx begins a transaction, which is passed to the hook, if it's a [sqlite.TxHook].
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	txHook, ok := c.hook.(TxHook)
+	if !ok {
+		return c.trackTx(ctx, opts)
+	}
+	ctx = txHook.BeforeTx(ctx)
+	tx, err := c.trackTx(ctx, opts)
+	if err != nil {
+		txHook.AfterTx(ctx, TxOpBegin, err)
+		return nil, err
+	}
+	return &hookTx{Tx: tx, ctx: ctx, hook: txHook}, nil
+}
+
+// trackTx begins a transaction, which is tracked by activity, if set.
+func (c *conn) trackTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.activity == nil {
+		return c.beginTx(ctx, opts)
+	}
+	done, err := c.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The BEGIN statement of a [sqlite.TxMode] is already part of the transaction.
+	c.inTx = true
+	tx, err := c.beginTx(ctx, opts)
+	if err != nil {
+		c.inTx = false
+		done()
+		return nil, err
+	}
+	return &activeTx{Tx: tx, conn: c, done: done}, nil
+}
+
+func (c *conn) beginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if mode := txModeFromContext(ctx); mode != TxDefault {
+		return beginTxMode(ctx, c, mode)
+	}
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // The same fallback is used by database/sql.
+}
+
+// track starts an operation of activity and returns the func to end it.
+//
+// An operation within a transaction is not tracked on its own, it's already part of the transaction.
+func (c *conn) track(ctx context.Context) (func(), error) {
+	if c.activity == nil || c.inTx {
+		return func() {}, nil
+	}
+	if err := c.activity.begin(ctx); err != nil {
+		return nil, err
+	}
+	return c.activity.end, nil
+}
+
+// trackRows ends the operation of done when rows are closed, the driver reads them until then.
+func (c *conn) trackRows(rows driver.Rows, err error, done func()) (driver.Rows, error) {
+	if err != nil {
+		done()
+		return nil, err
+	}
+	if c.activity == nil || c.inTx {
+		return rows, nil
+	}
+	return &activeRows{Rows: rows, done: done}, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps the [driver.Stmt] of the driver, so all executions of a prepared statement are passed to the hook of conn
+// and tracked by its activity.
+type stmt struct {
+	driver.Stmt
+	conn  *conn
+	query string
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	done, err := s.conn.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return runHook(ctx, s.conn.hook, s.query, args, func(ctx context.Context) (driver.Result, error) {
+		if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+			return execer.ExecContext(ctx, args)
+		}
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Stmt.Exec(values) //nolint:staticcheck // The same fallback is used by database/sql.
+	})
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	done, err := s.conn.track(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := runHook(ctx, s.conn.hook, s.query, args, func(ctx context.Context) (driver.Rows, error) {
+		if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			return queryer.QueryContext(ctx, args)
+		}
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Stmt.Query(values) //nolint:staticcheck // The same fallback is used by database/sql.
+	})
+	return s.conn.trackRows(rows, err, done)
+}
+
+// CheckNamedValue uses the checker of the statement or of its connection like [database/sql].
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+// namedValuesToValues converts args for the deprecated methods of [driver.Stmt], which don't support names.
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgsUnsupported
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
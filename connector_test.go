@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// buildMockConnector returns a connector for a new sqlmock connection registered as dsn.
+func buildMockConnector(t *testing.T, dsn string) (driver.Connector, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return dsnConnector{driver: db.Driver(), dsn: dsn}, mock
+}
+
+func Test_initConnector(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ATTACH DATABASE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	var called int
+	db := sql.OpenDB(&initConnector{
+		connector: connector,
+		init: []ConnInitFunc{
+			execInit([]string{"PRAGMA journal_size_limit = 100;"}),
+			func(ctx context.Context, conn *sql.Conn) error {
+				called++
+				_, err := conn.ExecContext(ctx, "ATTACH DATABASE 'file:other.db' AS other;")
+				return err
+			},
+		},
+	})
+	defer db.Close()
+
+	var one int
+	if err := db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if called != 1 {
+		t.Errorf("expected '%d', got '%d'", 1, called)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_initConnector_Error(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectClose()
+
+	db := sql.OpenDB(&initConnector{
+		connector: connector,
+		init: []ConnInitFunc{
+			func(context.Context, *sql.Conn) error {
+				return errUnitTest
+			},
+		},
+	})
+	defer db.Close()
+
+	if err := db.Ping(); !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_singleConnector_Reused(t *testing.T) {
+	t.Parallel()
+
+	connector := &singleConnector{conn: noCloseConn{}}
+	if _, err := connector.Connect(context.Background()); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := connector.Connect(context.Background()); !errors.Is(err, errConnInitReused) {
+		t.Fatalf("expect error to be '%s', got '%s'", errConnInitReused, err)
+	}
+}
+
+func TestUnwrapConn(t *testing.T) {
+	t.Parallel()
+
+	connector, _ := buildMockConnector(t, t.Name())
+	inner, err := connector.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer inner.Close()
+
+	got := UnwrapConn(&conn{Conn: &conn{Conn: inner}})
+	if got != inner {
+		t.Errorf("expected '%v', got '%v'", inner, got)
+	}
+}
+
+func Test_lookupConnector(t *testing.T) {
+	t.Parallel()
+
+	connector, _ := buildMockConnector(t, t.Name())
+	ic := &initConnector{connector: connector}
+	db := sql.OpenDB(ic)
+	defer db.Close()
+
+	if got := lookupConnector(db); got != ic {
+		t.Errorf("expected '%v', got '%v'", ic, got)
+	}
+	if got := UnwrapDriver(db.Driver()); got != connector.Driver() {
+		t.Errorf("expected '%v', got '%v'", connector.Driver(), got)
+	}
+
+	other := sql.OpenDB(connector)
+	defer other.Close()
+	if got := lookupConnector(other); got != nil {
+		t.Errorf("expected '%v', got '%v'", nil, got)
+	}
+}
+
+func Test_openConnector_UnknownDriver(t *testing.T) {
+	t.Parallel()
+
+	if _, err := openConnector("unknown", ""); err == nil {
+		t.Fatal("expected an error for an unknown driver")
+	}
+}
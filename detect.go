@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// EnvDriver is the environment variable to select the [sqlite.Driver], like "modernc.org/sqlite".
+//
+// It overrides [sqlite.WithDriverPreference], but not [sqlite.WithDriver].
+const EnvDriver = "SQLITE_DRIVER"
+
+// ErrDriverNotRegistered will be returned if none of the preferred drivers is registered,
+// see [sqlite.WithDriverPreference] and [sqlite.EnvDriver].
+var ErrDriverNotRegistered = errors.New("preferred driver is not registered")
+
+// ErrAmbiguousDriver will be returned if "github.com/mattn/go-sqlite3" and "github.com/ncruces/go-sqlite3" are
+// both detected without a preference, see [sqlite.WithDriverPreference] and [sqlite.EnvDriver].
+var ErrAmbiguousDriver = errors.New("ambiguous drivers detected")
+
+// maxWrapDepth limits how deep wrapped drivers are searched by [sqlite.DetectedDrivers].
+const maxWrapDepth = 3
+
+var driverType = reflect.TypeOf((*driver.Driver)(nil)).Elem()
+
+// DetectedDriver is a registered [database/sql] driver, which is recognized by a [sqlite.DriverAdapter].
+type DetectedDriver struct {
+	Driver     Driver // Driver of the recognizing [sqlite.DriverAdapter]
+	DriverName string // DriverName used in [sql.Register]
+}
+
+func (d DetectedDriver) String() string {
+	return fmt.Sprintf("'%s' (%s)", d.DriverName, d.Driver)
+}
+
+// DetectedDrivers will return all registered [database/sql] drivers, which are recognized by a [sqlite.DriverAdapter].
+// They are sorted by their name.
+//
+// A driver is recognized by the package path of its type. Wrappers for tracing, profiling, etc.
+// registered under another name are recognized, if they keep the wrapped [driver.Driver] in a field.
+// Drivers with an unknown type are recognized by the default name of an adapter, like "sqlite3".
+func DetectedDrivers() []DetectedDriver {
+	adapters := registeredAdapters()
+
+	var detected []DetectedDriver
+	for _, name := range sql.Drivers() {
+		if adapter, ok := recognizeDriver(adapters, name); ok {
+			detected = append(detected, DetectedDriver{Driver: adapter.Driver(), DriverName: name})
+		}
+	}
+	return detected
+}
+
+// selectDriver sets config.Driver and config.DriverName, if no [sqlite.Driver] was set per [sqlite.WithDriver].
+func selectDriver(config *Config) error {
+	if config.Driver != "" {
+		return nil
+	}
+
+	preference := config.DriverPreference
+	if env := os.Getenv(EnvDriver); env != "" {
+		preference = []Driver{Driver(env)}
+	}
+
+	detected, err := preferDriver(DetectedDrivers(), preference)
+	if err != nil {
+		return err
+	}
+	config.Driver = detected.Driver
+	if config.DriverName == "" {
+		config.DriverName = detected.DriverName
+	}
+	return nil
+}
+
+// preferDriver returns the first detected driver of preference, or the first detected driver without a preference.
+//
+// Drivers registered with the default name of their adapter are preferred over wrappers.
+// Without a preference, the drivers sharing the name "sqlite3" are ambiguous.
+func preferDriver(detected []DetectedDriver, preference []Driver) (DetectedDriver, error) {
+	if len(preference) == 0 {
+		_, mattn := findDetected(detected, DriverMattn)
+		_, ncruces := findDetected(detected, DriverNcruces)
+		if mattn && ncruces {
+			return DetectedDriver{}, fmt.Errorf("detected %v: %w", detected, ErrAmbiguousDriver)
+		}
+		if d, ok := findDetected(detected, ""); ok {
+			return d, nil
+		}
+		return DetectedDriver{}, ErrCantDetectDriver
+	}
+
+	for _, p := range preference {
+		if _, ok := lookupDriverAdapter(p); !ok {
+			return DetectedDriver{}, fmt.Errorf("given '%s', %w", p, ErrUnknownDriver)
+		}
+		if d, ok := findDetected(detected, p); ok {
+			return d, nil
+		}
+	}
+	return DetectedDriver{}, fmt.Errorf("preferred %v, detected %v: %w", preference, detected, ErrDriverNotRegistered)
+}
+
+// findDetected returns the detected driver for d, an empty d matches every driver.
+func findDetected(detected []DetectedDriver, d Driver) (DetectedDriver, bool) {
+	var wrapped []DetectedDriver
+	for _, dd := range detected {
+		if d != "" && dd.Driver != d {
+			continue
+		}
+		if adapter, ok := lookupDriverAdapter(dd.Driver); ok && adapter.DriverName() == dd.DriverName {
+			return dd, true
+		}
+		wrapped = append(wrapped, dd)
+	}
+	if len(wrapped) == 0 {
+		return DetectedDriver{}, false
+	}
+	return wrapped[0], true
+}
+
+// recognizeDriver returns the adapter for the registered driver driverName.
+func recognizeDriver(adapters []DriverAdapter, driverName string) (DriverAdapter, bool) {
+	for _, pkgPath := range driverPkgPaths(driverName) {
+		for _, a := range adapters {
+			d := string(a.Driver())
+			if pkgPath == d || strings.HasPrefix(pkgPath, d+"/") {
+				return a, true
+			}
+		}
+	}
+	for _, a := range adapters {
+		if a.DriverName() == driverName {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// driverPkgPaths returns the package path of the registered driver driverName and of the drivers it wraps.
+func driverPkgPaths(driverName string) []string {
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	return appendPkgPaths(nil, reflect.ValueOf(db.Driver()), 0)
+}
+
+func appendPkgPaths(paths []string, v reflect.Value, depth int) []string {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return paths
+		}
+		v = v.Elem()
+	}
+	if pkgPath := v.Type().PkgPath(); pkgPath != "" {
+		paths = append(paths, pkgPath)
+	}
+	if v.Kind() != reflect.Struct || depth >= maxWrapDepth {
+		return paths
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() == reflect.Interface {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+		if f.Type().Implements(driverType) {
+			paths = appendPkgPaths(paths, f, depth+1)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const driverSqlmock Driver = "github.com/DATA-DOG/go-sqlmock"
+
+// wrappedDriver is shaped like the drivers of tracing wrappers.
+type wrappedDriver struct {
+	parent driver.Driver
+}
+
+func (w wrappedDriver) Open(name string) (driver.Conn, error) {
+	return w.parent.Open(name)
+}
+
+// registerDetectDrivers registers an adapter for "github.com/DATA-DOG/go-sqlmock" and a wrapper of its driver.
+func registerDetectDrivers(t *testing.T) {
+	t.Helper()
+
+	registerUnitTestAdapter(t, unitTestAdapter{driver: driverSqlmock, driverName: "sqlmock"})
+
+	sql.Register("otel-sqlmock", wrappedDriver{parent: drivers["sqlmock"]})
+	t.Cleanup(func() {
+		delete(drivers, "otel-sqlmock")
+	})
+}
+
+func TestDetectedDrivers(t *testing.T) {
+	registerDetectDrivers(t)
+
+	expected := []DetectedDriver{
+		{Driver: driverSqlmock, DriverName: "otel-sqlmock"},
+		{Driver: driverSqlmock, DriverName: "sqlmock"},
+	}
+	got := DetectedDrivers()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+}
+
+func Test_selectDriver(t *testing.T) {
+	registerDetectDrivers(t)
+
+	tests := []struct {
+		name    string
+		env     string
+		opts    []Option
+		want    DetectedDriver
+		wantErr error
+	}{
+		{"Default name before wrapper", "", nil, DetectedDriver{driverSqlmock, "sqlmock"}, nil},
+		{"Preference", "", []Option{WithDriverPreference(DriverMattn, driverSqlmock)}, DetectedDriver{driverSqlmock, "sqlmock"}, nil},
+		{"Preference with wrapper name", "", []Option{WithDriverPreference(driverSqlmock), WithDriverName("otel-sqlmock")}, DetectedDriver{driverSqlmock, "otel-sqlmock"}, nil},
+		{"Preference not registered", "", []Option{WithDriverPreference(DriverMattn, DriverModernc)}, DetectedDriver{}, ErrDriverNotRegistered},
+		{"Preference unknown", "", []Option{WithDriverPreference("example.com/unknown")}, DetectedDriver{}, ErrUnknownDriver},
+		{"Environment", string(driverSqlmock), []Option{WithDriverPreference(DriverMattn)}, DetectedDriver{driverSqlmock, "sqlmock"}, nil},
+		{"Environment not registered", string(DriverModernc), nil, DetectedDriver{}, ErrDriverNotRegistered},
+		{"WithDriver ignores the environment", string(driverSqlmock), []Option{WithDriver(DriverMattn)}, DetectedDriver{DriverMattn, ""}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvDriver, tc.env)
+
+			config := newConfig()
+			optionRunner(config, tc.opts...)
+
+			err := selectDriver(config)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+
+			got := DetectedDriver{Driver: config.Driver, DriverName: config.DriverName}
+			if got != tc.want {
+				t.Errorf("expected '%s', got '%s'", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_preferDriver_Ambiguous(t *testing.T) {
+	t.Parallel()
+
+	detected := []DetectedDriver{{DriverNcruces, "ncruces"}, {DriverMattn, DriverNameMattn}}
+
+	if _, err := preferDriver(detected, nil); !errors.Is(err, ErrAmbiguousDriver) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrAmbiguousDriver, err)
+	}
+	got, err := preferDriver(detected, []Driver{DriverNcruces})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if expected := (DetectedDriver{DriverNcruces, "ncruces"}); got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
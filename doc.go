@@ -11,6 +11,8 @@
 		_ "github.com/mattn/go-sqlite3"
 		// or
 		_ "modernc.org/sqlite"
+		// or
+		_ "github.com/ncruces/go-sqlite3/driver"
 
 		"github.com/lanz-dev/go-sqlite"
 	)
@@ -25,8 +27,19 @@ func main() {
 		db.Exec("SELECT 1")
 	}
 
+# Drivers
+
+"github.com/mattn/go-sqlite3" and "github.com/ncruces/go-sqlite3/driver" both register the driver name "sqlite3",
+so importing both panics on the duplicate [sql.Register].
+If one of them is registered under another name, e.g. by a wrapper, [sqlite.Connect] needs
+[sqlite.WithDriver] or [sqlite.WithDriverPreference] to select one of them.
+
 # Changelog
 
+Unreleased
+  - Breaking: [sql.DB.Driver] and [sql.Conn.Raw] return wrapped types,
+    type assertions to the types of the driver like *sqlite3.SQLiteConn need [sqlite.UnwrapDriver] and [sqlite.UnwrapConn]
+
 v0.1.0
   - Initial Release
 */
@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDSN will be returned if a DSN can't be parsed.
+var ErrInvalidDSN = errors.New("invalid DSN provided")
+
+// ParseDSN will parse a DSN written for driver back into a [sqlite.Config].
+//
+// These parameters are understood:
+//   - [sqlite.DriverMattn]: _auto_vacuum, _busy_timeout, _cache_size, _case_sensitive_like, _defer_foreign_keys,
+//     _foreign_keys, _journal_mode, _locking_mode, _query_only, _recursive_triggers, _secure_delete,
+//     _synchronous and their short aliases like _fk or _sync
+//   - [sqlite.DriverModernc] and [sqlite.DriverNcruces]: _pragma=name(value) and _pragma=name=value for the pragmas of [sqlite.Config]
+//   - all drivers: _txlock
+//
+// All other parameters, like the SQLite URI parameters "mode" or "cache", are kept in [sqlite.Config.Path].
+// An empty driver understands the parameters of both drivers.
+//
+// The returned [sqlite.Config] can be used to build the DSN for another driver.
+func ParseDSN(dsn string, driver Driver) (*Config, error) {
+	config := &Config{Driver: driver}
+	if err := parseDSN(config, dsn, driver); err != nil {
+		return nil, err
+	}
+	if err := validatePath(config.Path); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parseDSN sets the path and all pragmas found in dsn on config.
+func parseDSN(config *Config, dsn string, driver Driver) error {
+	path, query, _ := strings.Cut(dsn, "?")
+
+	kept := url.Values{}
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+
+		rawKey, rawValue, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return fmt.Errorf("given '%s', %w", param, ErrInvalidDSN)
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return fmt.Errorf("given '%s', %w", param, ErrInvalidDSN)
+		}
+
+		ok := false
+		if key == "_txlock" {
+			if config.TxLock, err = parseTxMode(value); err != nil {
+				return fmt.Errorf("parameter '%s': %w", key, err)
+			}
+			ok = true
+		}
+		if !ok && (driver == "" || driver == DriverMattn) {
+			if ok, err = parseMattnParam(config, key, value); err != nil {
+				return err
+			}
+		}
+		if !ok && key == "_pragma" && (driver == "" || driver == DriverModernc || driver == DriverNcruces) {
+			if err := parseModerncPragma(config, value); err != nil {
+				return err
+			}
+			ok = true
+		}
+		if !ok {
+			kept.Add(key, value)
+		}
+	}
+
+	config.Path = encodeDSN(path, kept)
+	return nil
+}
+
+// encodeDSN returns path with params merged into the query string of path.
+//
+// All keys and values are escaped, so a value can't add another parameter.
+// The existing parameters of path are kept, a query string which can't be parsed is rejected by [sqlite.validatePath].
+func encodeDSN(path string, params url.Values) string {
+	name, query, _ := strings.Cut(path, "?")
+	values, _ := url.ParseQuery(query)
+	for key, vs := range params {
+		values[key] = append(values[key], vs...)
+	}
+	if len(values) == 0 {
+		return name
+	}
+	return name + "?" + encodeQuery(values)
+}
+
+// encodeQuery is [url.Values.Encode], but keeps the parentheses of "_pragma=name(value)" readable.
+//
+// Parentheses have no meaning within a query string, so all drivers parse them the same either way.
+func encodeQuery(values url.Values) string {
+	return strings.NewReplacer("%28", "(", "%29", ")").Replace(values.Encode())
+}
+
+func parseMattnParam(config *Config, key, value string) (bool, error) {
+	var err error
+	switch key {
+	case "_auto_vacuum", "_vacuum":
+		config.AutoVacuumMode, err = parseAutoVacuumMode(value)
+	case "_busy_timeout", "_timeout":
+		config.BusyTimeout, err = parseInt(value)
+	case "_cache_size":
+		config.CacheSize, err = parseInt(value)
+	case "_case_sensitive_like", "_cslike":
+		config.CaseSensitiveLike, err = parseBool(value)
+	case "_defer_foreign_keys", "_defer_fk", "defer_foreign_keys":
+		config.DeferForeignKeys, err = parseBool(value)
+	case "_foreign_keys", "_fk":
+		config.ForeignKey, err = parseBool(value)
+	case "_journal_mode", "_journal":
+		config.JournalMode, err = parseJournalMode(value)
+	case "_locking_mode", "_locking":
+		config.LockingMode, err = parseLockingMode(value)
+	case "_query_only":
+		config.QueryOnly, err = parseBool(value)
+	case "_recursive_triggers", "_rt":
+		config.RecursiveTriggers, err = parseBool(value)
+	case "_secure_delete":
+		config.SecureDelete, err = parseSecureDelete(value)
+	case "_synchronous", "_sync":
+		config.SyncMode, err = parseSyncMode(value)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("parameter '%s': %w", key, err)
+	}
+	return true, nil
+}
+
+// parseModerncPragma parses the value of a "_pragma" parameter, "name(value)" or "name=value".
+func parseModerncPragma(config *Config, pragma string) error {
+	var name, value string
+	if i := strings.IndexAny(pragma, "(="); i != -1 {
+		name, value = pragma[:i], pragma[i+1:]
+		if pragma[i] == '(' {
+			if !strings.HasSuffix(value, ")") {
+				return fmt.Errorf("given '_pragma=%s', %w", pragma, ErrInvalidDSN)
+			}
+			value = strings.TrimSuffix(value, ")")
+		}
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	value = strings.TrimSpace(value)
+
+	var err error
+	switch name {
+	case "auto_vacuum":
+		config.AutoVacuumMode, err = parseAutoVacuumMode(value)
+	case "busy_timeout":
+		config.BusyTimeout, err = parseInt(value)
+	case "cache_size":
+		config.CacheSize, err = parseInt(value)
+	case "case_sensitive_like":
+		config.CaseSensitiveLike, err = parseBool(value)
+	case "cell_size_check":
+		config.CellSizeCheck, err = parseBool(value)
+	case "defer_foreign_keys":
+		config.DeferForeignKeys, err = parseBool(value)
+	case "foreign_keys":
+		config.ForeignKey, err = parseBool(value)
+	case "journal_mode":
+		config.JournalMode, err = parseJournalMode(value)
+	case "journal_size_limit":
+		config.JournalSizeLimit, err = parseInt(value)
+	case "locking_mode":
+		config.LockingMode, err = parseLockingMode(value)
+	case "mmap_size":
+		config.MmapSize, err = parseInt64(value)
+	case "page_size":
+		config.PageSize, err = parseInt(value)
+	case "query_only":
+		config.QueryOnly, err = parseBool(value)
+	case "recursive_triggers":
+		config.RecursiveTriggers, err = parseBool(value)
+	case "secure_delete":
+		config.SecureDelete, err = parseSecureDelete(value)
+	case "synchronous":
+		config.SyncMode, err = parseSyncMode(value)
+	case "temp_store":
+		config.TempStore, err = parseTempStore(value)
+	case "wal_autocheckpoint":
+		config.WALAutoCheckpoint, err = parseInt(value)
+	default:
+		return fmt.Errorf("given unsupported '_pragma=%s', %w", pragma, ErrInvalidDSN)
+	}
+	if err != nil {
+		return fmt.Errorf("pragma '%s': %w", name, err)
+	}
+	return nil
+}
+
+func parseInt(value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+	}
+	return i, nil
+}
+
+func parseInt64(value string) (int64, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+	}
+	return i, nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true", "on":
+		return true, nil
+	case "0", "no", "false", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseAutoVacuumMode(value string) (AutoVacuumMode, error) {
+	switch strings.ToUpper(value) {
+	case "0", string(AutoVacuumNone):
+		return AutoVacuumNone, nil
+	case "1", string(AutoVacuumFull):
+		return AutoVacuumFull, nil
+	case "2", string(AutoVacuumIncremental):
+		return AutoVacuumIncremental, nil
+	}
+	return AutoVacuumDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseJournalMode(value string) (JournalMode, error) {
+	mode := JournalMode(strings.ToUpper(value))
+	switch mode {
+	case JournalDelete, JournalTruncate, JournalPersist, JournalMemory, JournalWAL, JournalOff:
+		return mode, nil
+	}
+	return JournalDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseLockingMode(value string) (LockingMode, error) {
+	mode := LockingMode(strings.ToUpper(value))
+	switch mode {
+	case LockingNormal, LockingExclusive:
+		return mode, nil
+	}
+	return LockingDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseSecureDelete(value string) (SecureDelete, error) {
+	if strings.EqualFold(value, string(SecureDeleteFast)) || value == "2" {
+		return SecureDeleteFast, nil
+	}
+	enabled, err := parseBool(value)
+	if err != nil {
+		return SecureDeleteDefault, err
+	}
+	if enabled {
+		return SecureDeleteOn, nil
+	}
+	return SecureDeleteOff, nil
+}
+
+func parseSyncMode(value string) (SyncMode, error) {
+	switch strings.ToUpper(value) {
+	case "0", string(SyncOff):
+		return SyncOff, nil
+	case "1", string(SyncNormal):
+		return SyncNormal, nil
+	case "2", string(SyncFull):
+		return SyncFull, nil
+	case "3", string(SyncExtra):
+		return SyncExtra, nil
+	}
+	return SyncDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseTempStore(value string) (TempStore, error) {
+	switch strings.ToUpper(value) {
+	case "0", "DEFAULT":
+		return TempStoreDefault, nil
+	case "1", string(TempStoreFile):
+		return TempStoreFile, nil
+	case "2", string(TempStoreMemory):
+		return TempStoreMemory, nil
+	}
+	return TempStoreDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
+
+func parseTxMode(value string) (TxMode, error) {
+	mode := TxMode(strings.ToUpper(value))
+	switch mode {
+	case TxDeferred, TxImmediate, TxExclusive:
+		return mode, nil
+	}
+	return TxDefault, fmt.Errorf("given '%s', %w", value, ErrInvalidDSN)
+}
@@ -0,0 +1,304 @@
+package sqlite
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		name   string
+		dsn    string
+		driver Driver
+		want   Config
+	}{
+		{
+			"Mattn",
+			"file:data.db?_auto_vacuum=2&_timeout=100&_case_sensitive_like=true&_fk=true&defer_foreign_keys=true&_journal=PERSIST&_query_only=1&_sync=3",
+			DriverMattn,
+			Config{
+				Driver:            DriverMattn,
+				Path:              "file:data.db",
+				AutoVacuumMode:    AutoVacuumIncremental,
+				BusyTimeout:       100,
+				CaseSensitiveLike: true,
+				DeferForeignKeys:  true,
+				ForeignKey:        true,
+				JournalMode:       JournalPersist,
+				QueryOnly:         true,
+				SyncMode:          SyncExtra,
+			},
+		},
+		{
+			"MattnLongNames",
+			"file:data.db?_vacuum=full&_busy_timeout=5&_foreign_keys=on&_journal_mode=wal&_synchronous=normal",
+			DriverMattn,
+			Config{
+				Driver:         DriverMattn,
+				Path:           "file:data.db",
+				AutoVacuumMode: AutoVacuumFull,
+				BusyTimeout:    5,
+				ForeignKey:     true,
+				JournalMode:    JournalWAL,
+				SyncMode:       SyncNormal,
+			},
+		},
+		{
+			"Modernc",
+			"file:data.db?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(100)&_pragma=case_sensitive_like(1)&_pragma=foreign_keys(1)&_pragma=defer_foreign_keys(1)&_pragma=journal_mode(PERSIST)&_pragma=journal_size_limit(42)&_pragma=query_only(1)&_pragma=synchronous(EXTRA)",
+			DriverModernc,
+			Config{
+				Driver:            DriverModernc,
+				Path:              "file:data.db",
+				AutoVacuumMode:    AutoVacuumIncremental,
+				BusyTimeout:       100,
+				CaseSensitiveLike: true,
+				DeferForeignKeys:  true,
+				ForeignKey:        true,
+				JournalMode:       JournalPersist,
+				JournalSizeLimit:  42,
+				QueryOnly:         true,
+				SyncMode:          SyncExtra,
+			},
+		},
+		{
+			"MattnPragmas",
+			"file:data.db?_cache_size=-2000&_locking=exclusive&_rt=1&_secure_delete=fast",
+			DriverMattn,
+			Config{
+				Driver:            DriverMattn,
+				Path:              "file:data.db",
+				CacheSize:         -2000,
+				LockingMode:       LockingExclusive,
+				RecursiveTriggers: true,
+				SecureDelete:      SecureDeleteFast,
+			},
+		},
+		{
+			"ModerncPragmas",
+			"file:data.db?_pragma=page_size(8192)&_pragma=cache_size(-2000)&_pragma=cell_size_check(1)&_pragma=locking_mode(EXCLUSIVE)&_pragma=mmap_size(268435456)&_pragma=recursive_triggers(1)&_pragma=secure_delete(ON)&_pragma=temp_store(MEMORY)&_pragma=wal_autocheckpoint(500)",
+			DriverModernc,
+			Config{
+				Driver:            DriverModernc,
+				Path:              "file:data.db",
+				CacheSize:         -2000,
+				CellSizeCheck:     true,
+				LockingMode:       LockingExclusive,
+				MmapSize:          268435456,
+				PageSize:          8192,
+				RecursiveTriggers: true,
+				SecureDelete:      SecureDeleteOn,
+				TempStore:         TempStoreMemory,
+				WALAutoCheckpoint: 500,
+			},
+		},
+		{
+			"ModerncAssignment",
+			"file:data.db?_pragma=journal_mode%3Dwal&_pragma=busy_timeout%3D10",
+			DriverModernc,
+			Config{
+				Driver:      DriverModernc,
+				Path:        "file:data.db",
+				BusyTimeout: 10,
+				JournalMode: JournalWAL,
+			},
+		},
+		{
+			"TxLock",
+			"file:data.db?_txlock=exclusive",
+			DriverModernc,
+			Config{
+				Driver: DriverModernc,
+				Path:   "file:data.db",
+				TxLock: TxExclusive,
+			},
+		},
+		{
+			"KeepsUnknownParameters",
+			"file:data.db?mode=ro&_fk=1&cache=shared&_loc=auto",
+			DriverMattn,
+			Config{
+				Driver:     DriverMattn,
+				Path:       "file:data.db?_loc=auto&cache=shared&mode=ro",
+				ForeignKey: true,
+			},
+		},
+		{
+			"MattnParameterForModernc",
+			"file:data.db?_fk=1",
+			DriverModernc,
+			Config{
+				Driver: DriverModernc,
+				Path:   "file:data.db?_fk=1",
+			},
+		},
+		{
+			"BothDialects",
+			"file:data.db?_fk=1&_pragma=synchronous(FULL)",
+			"",
+			Config{
+				Path:       "file:data.db",
+				ForeignKey: true,
+				SyncMode:   SyncFull,
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseDSN(tc.dsn, tc.driver)
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Fatalf("expected '%+v', got '%+v'", tc.want, *got)
+			}
+		})
+	}
+}
+
+func TestParseDSN_Errors(t *testing.T) {
+	tests := []struct {
+		dsn     string
+		driver  Driver
+		wantErr error
+	}{
+		{"file:data.db?_fk=maybe", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_timeout=soon", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_journal=fast", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_sync=4", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_vacuum=3", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_locking=shared", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_txlock=later", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_secure_delete=slow", DriverMattn, ErrInvalidDSN},
+		{"file:data.db?_pragma=temp_store(DISK)", DriverModernc, ErrInvalidDSN},
+		{"file:data.db?_pragma=user_version(10)", DriverModernc, ErrInvalidDSN},
+		{"file:data.db?_pragma=journal_mode(WAL", DriverModernc, ErrInvalidDSN},
+		{"file:data.db?_pragma=%zz", DriverModernc, ErrInvalidDSN},
+		{"file://host/data.db?_fk=1", DriverMattn, ErrInvalidPath},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run("With DSN '"+tc.dsn+"'", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseDSN(tc.dsn, tc.driver)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseDSN_MattnToModernc(t *testing.T) {
+	t.Parallel()
+
+	config, err := ParseDSN("file:data.db?mode=ro&_timeout=4000&_fk=true&_journal=WAL&_sync=1", DriverMattn)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	config.Driver = DriverModernc
+
+	dsn := buildModerncDSN(config)
+	expected := "file:data.db?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&mode=ro"
+	if dsn != expected {
+		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
+	}
+}
+
+func TestWithDSN(t *testing.T) {
+	t.Parallel()
+
+	config, err := buildConfig(
+		WithDriver(DriverMattn),
+		WithDSN("file:data.db?_pragma=synchronous(FULL)&_fk=0"),
+		WithBusyTimeout(100),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	expected := "file:data.db?_journal=WAL&_sync=2&_timeout=100"
+	if config.DSN != expected {
+		t.Fatalf("expected '%s', got '%s'", expected, config.DSN)
+	}
+}
+
+func TestWithDSN_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(
+		WithDriver(DriverMattn),
+		WithDSN("file:data.db?_fk=maybe"),
+	)
+	if !errors.Is(err, ErrInvalidDSN) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidDSN, err)
+	}
+}
+
+func Test_encodeDSN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		params   url.Values
+		expected string
+	}{
+		{":memory", nil, ":memory"},
+		{"file:data.db?mode=ro", url.Values{"_fk": {"true"}, "_sync": {"1"}}, "file:data.db?_fk=true&_sync=1&mode=ro"},
+		{"file:data.db?_pragma=foreign_keys(1)", url.Values{"_pragma": {"busy_timeout(100)"}}, "file:data.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(100)"},
+		{"data.db", url.Values{"_journal": {"WAL)&_pragma=query_only(1)"}}, "data.db?_journal=WAL)%26_pragma%3Dquery_only(1)"},
+	}
+	for _, tc := range tests {
+		if got := encodeDSN(tc.path, tc.params); got != tc.expected {
+			t.Errorf("expected '%s', got '%s'", tc.expected, got)
+		}
+	}
+}
+
+// FuzzDSN builds the DSN of a config with a parameter within its path and parses it back for both drivers.
+//
+// No value may add another parameter to the DSN or change any other value.
+func FuzzDSN(f *testing.F) {
+	f.Add("data.db", "x", "WAL", "NORMAL", "IMMEDIATE")
+	f.Add("data.db", "WAL)&_pragma=query_only(1)", "WAL)&_pragma=query_only(1)", "FULL", "")
+	f.Add("dir/my data.db", "a=b&_fk=0", "DELETE", "OFF)&_sync=3", "deferred")
+	f.Add("data.db#x?y", "%zz;&", "wal", "1", "EXCLUSIVE")
+
+	f.Fuzz(func(t *testing.T, name, param, journal, sync, txLock string) {
+		for _, driver := range []Driver{DriverMattn, DriverModernc} {
+			config := newConfig()
+			config.Driver = driver
+			config.Path = FilePath(name).String() + "?" + url.Values{"unittest": {param}}.Encode()
+			config.JournalMode = JournalMode(journal)
+			config.SyncMode = SyncMode(sync)
+			config.TxLock = TxMode(txLock)
+			if config.Validate() != nil {
+				return
+			}
+
+			dsn := buildDriverDSN(config)
+			got, err := ParseDSN(dsn, driver)
+			if err != nil {
+				t.Fatalf("did not expect error '%s' for '%s'", err, dsn)
+			}
+			if got.JournalMode != config.JournalMode || got.SyncMode != config.SyncMode || got.TxLock != config.TxLock ||
+				got.BusyTimeout != config.BusyTimeout || got.ForeignKey != config.ForeignKey {
+				t.Fatalf("expected '%+v', got '%+v' for '%s'", config, got, dsn)
+			}
+
+			path, query, _ := strings.Cut(got.Path, "?")
+			values, err := url.ParseQuery(query)
+			if err != nil {
+				t.Fatalf("did not expect error '%s' for '%s'", err, dsn)
+			}
+			if path != FilePath(name).String() || len(values) != 1 || values.Get("unittest") != param {
+				t.Fatalf("expected the path of '%s' unchanged, got '%s'", config.Path, got.Path)
+			}
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RegisterFlags will register a flag for every setting of c, which can be written as text, like "-sqlite-journal-mode".
+//
+// The flag names are the kebab-case names of the tags of [sqlite.Config] with prefix, like "sqlite-".
+// The current values of c are the defaults of the flags, use it with [sqlite.DefaultConfig]:
+//
+//	config := sqlite.DefaultConfig()
+//	config.RegisterFlags(flag.CommandLine, "sqlite-")
+//	flag.Parse()
+//	db, err := sqlite.Connect(sqlite.WithConfig(config))
+//
+// The busy timeout is a duration like "5s" or the milliseconds like "5000".
+func (c *Config) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	fs.StringVar(&c.Path, prefix+"path", c.Path, "path of the database, a file: URI, a plain path or :memory:")
+	fs.TextVar(&c.Driver, prefix+"driver", c.Driver, "driver like modernc.org/sqlite, detected if empty")
+	fs.StringVar(&c.DriverName, prefix+"driver-name", c.DriverName, "name of the driver for sql.Open")
+	fs.BoolVar(&c.CreateDirs, prefix+"create-dirs", c.CreateDirs, "create the parent directories of the database file")
+	fs.BoolVar(&c.LimitConnection, prefix+"limit-connection", c.LimitConnection, "limit the pool to a single connection")
+	fs.IntVar(&c.ReadConnections, prefix+"read-connections", c.ReadConnections, "max open connections of the reader pool")
+	fs.BoolVar(&c.StrictPragmas, prefix+"strict-pragmas", c.StrictPragmas, "verify the configured pragmas on connect")
+	fs.TextVar(&c.TxLock, prefix+"tx-lock", c.TxLock, "mode to begin all transactions: DEFERRED, IMMEDIATE or EXCLUSIVE")
+
+	fs.TextVar(&c.AutoVacuumMode, prefix+"auto-vacuum-mode", c.AutoVacuumMode, "auto vacuum mode: NONE, FULL or INCREMENTAL")
+	fs.Var((*busyTimeoutValue)(&c.BusyTimeout), prefix+"busy-timeout", "busy timeout, a duration or milliseconds")
+	fs.IntVar(&c.CacheSize, prefix+"cache-size", c.CacheSize, "cached pages, or KiB if negative")
+	fs.BoolVar(&c.CaseSensitiveLike, prefix+"case-sensitive-like", c.CaseSensitiveLike, "case-sensitive LIKE")
+	fs.BoolVar(&c.CellSizeCheck, prefix+"cell-size-check", c.CellSizeCheck, "additional sanity checks of database pages")
+	fs.BoolVar(&c.DeferForeignKeys, prefix+"defer-foreign-keys", c.DeferForeignKeys, "defer foreign key checks")
+	fs.BoolVar(&c.ForeignKey, prefix+"foreign-keys", c.ForeignKey, "foreign key support")
+	fs.TextVar(&c.JournalMode, prefix+"journal-mode", c.JournalMode, "journal mode: DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF")
+	fs.IntVar(&c.JournalSizeLimit, prefix+"journal-size-limit", c.JournalSizeLimit, "journal size limit in bytes, -1 for no limit")
+	fs.TextVar(&c.LockingMode, prefix+"locking-mode", c.LockingMode, "locking mode: NORMAL or EXCLUSIVE")
+	fs.Int64Var(&c.MmapSize, prefix+"mmap-size", c.MmapSize, "max bytes of memory-mapped I/O")
+	fs.IntVar(&c.PageSize, prefix+"page-size", c.PageSize, "page size of a new database")
+	fs.BoolVar(&c.QueryOnly, prefix+"query-only", c.QueryOnly, "prevent all changes to the database")
+	fs.BoolVar(&c.RecursiveTriggers, prefix+"recursive-triggers", c.RecursiveTriggers, "recursive triggers")
+	fs.TextVar(&c.SecureDelete, prefix+"secure-delete", c.SecureDelete, "secure delete mode: OFF, ON or FAST")
+	fs.TextVar(&c.SyncMode, prefix+"sync-mode", c.SyncMode, "sync mode: OFF, NORMAL, FULL or EXTRA")
+	fs.TextVar(&c.TempStore, prefix+"temp-store", c.TempStore, "temp store: FILE or MEMORY")
+	fs.IntVar(&c.WALAutoCheckpoint, prefix+"wal-autocheckpoint", c.WALAutoCheckpoint, "WAL auto checkpoint in pages, negative to disable")
+}
+
+// busyTimeoutValue is the [flag.Value] of the busy timeout in milliseconds, which accepts a duration as well.
+type busyTimeoutValue int
+
+func (v *busyTimeoutValue) String() string {
+	if v == nil {
+		return "0s"
+	}
+	return (time.Duration(*v) * time.Millisecond).String()
+}
+
+func (v *busyTimeoutValue) Set(s string) error {
+	if ms, err := strconv.Atoi(s); err == nil {
+		*v = busyTimeoutValue(ms)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("given busy timeout '%s', %w", s, ErrInvalidOption)
+	}
+	*v = busyTimeoutValue((d + time.Millisecond - 1) / time.Millisecond)
+	return nil
+}
+
+// FromEnv will return the options for all set environment variables, named like the flags of
+// [sqlite.Config.RegisterFlags] in upper snake case with prefix and "SQLITE_".
+//
+// For the prefix "APP_" the variables are "APP_SQLITE_PATH", "APP_SQLITE_JOURNAL_MODE", "APP_SQLITE_BUSY_TIMEOUT", etc.
+// The variable of the driver without prefix is [sqlite.EnvDriver], but it's used like [sqlite.WithDriver] here.
+// Empty variables are ignored. All invalid values are joined into the returned error.
+func FromEnv(prefix string) ([]Option, error) {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	newConfig().RegisterFlags(fs, "")
+
+	var (
+		opts []Option
+		errs []error
+	)
+	fs.VisitAll(func(f *flag.Flag) {
+		name := prefix + "SQLITE_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		value := os.Getenv(name)
+		if value == "" {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("variable '%s': %w", name, err))
+			return
+		}
+		opts = append(opts, withFlag(f.Name, value))
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// withFlag returns an [sqlite.Option] setting the flag name of [sqlite.Config.RegisterFlags] to value.
+func withFlag(name, value string) Option {
+	return OptionFunc(func(c *Config) error {
+		fs := flag.NewFlagSet("option", flag.ContinueOnError)
+		c.RegisterFlags(fs, "")
+		return fs.Set(name, value)
+	})
+}
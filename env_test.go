@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	fs := flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	config.RegisterFlags(fs, "sqlite-")
+
+	err := fs.Parse([]string{
+		"-sqlite-path", "file:data.db",
+		"-sqlite-journal-mode", "delete",
+		"-sqlite-sync-mode=2",
+		"-sqlite-busy-timeout", "1.5s",
+		"-sqlite-foreign-keys=false",
+		"-sqlite-mmap-size", "1048576",
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	expected := DefaultConfig()
+	expected.Path = "file:data.db"
+	expected.JournalMode = JournalDelete
+	expected.SyncMode = SyncFull
+	expected.BusyTimeout = 1500
+	expected.ForeignKey = false
+	expected.MmapSize = 1 << 20
+	if config.Path != expected.Path || config.JournalMode != expected.JournalMode || config.SyncMode != expected.SyncMode ||
+		config.BusyTimeout != expected.BusyTimeout || config.ForeignKey != expected.ForeignKey ||
+		config.MmapSize != expected.MmapSize || config.JournalSizeLimit != expected.JournalSizeLimit {
+		t.Errorf("expected '%+v', got '%+v'", expected, config)
+	}
+}
+
+func TestConfig_RegisterFlags_Invalid(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	fs := flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+	config.RegisterFlags(fs, "")
+
+	// The flag package doesn't wrap the error of the value.
+	err := fs.Parse([]string{"-journal-mode", "fast"})
+	if err == nil || !strings.Contains(err.Error(), ErrInvalidJournalMode.Error()) {
+		t.Fatalf("expect error to be '%s', got '%v'", ErrInvalidJournalMode, err)
+	}
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("APP_SQLITE_PATH", "file:data.db")
+	t.Setenv("APP_SQLITE_DRIVER", string(DriverModernc))
+	t.Setenv("APP_SQLITE_JOURNAL_MODE", "truncate")
+	t.Setenv("APP_SQLITE_BUSY_TIMEOUT", "5s")
+	t.Setenv("APP_SQLITE_FOREIGN_KEYS", "false")
+	t.Setenv("APP_SQLITE_CACHE_SIZE", "")
+
+	opts, err := FromEnv("APP_")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	config, err := buildConfig(opts...)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	expected := "file:data.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(TRUNCATE)&_pragma=synchronous(NORMAL)"
+	if config.DSN != expected {
+		t.Errorf("expected '%s', got '%s'", expected, config.DSN)
+	}
+}
+
+func TestFromEnv_Invalid(t *testing.T) {
+	t.Setenv("APP_SQLITE_JOURNAL_MODE", "fast")
+	t.Setenv("APP_SQLITE_DRIVER", "example.com/sqlite")
+	t.Setenv("APP_SQLITE_PAGE_SIZE", "large")
+
+	_, err := FromEnv("APP_")
+	for _, wantErr := range []error{ErrInvalidJournalMode, ErrUnknownDriver} {
+		if !errors.Is(err, wantErr) {
+			t.Errorf("expect error to be '%s', got '%s'", wantErr, err)
+		}
+	}
+	if got := len(err.(interface{ Unwrap() []error }).Unwrap()); got != 3 {
+		t.Errorf("expected '%d', got '%d'", 3, got)
+	}
+}
@@ -0,0 +1,191 @@
+package sqlite
+
+import "reflect"
+
+// Code is a result code of SQLite, either a primary or an extended result code.
+//
+// See https://www.sqlite.org/rescode.html.
+type Code int
+
+// Primary will return the primary result code, the least significant 8 bits of an extended result code.
+func (c Code) Primary() Code {
+	return c & 0xff
+}
+
+// The primary result codes of SQLite.
+//
+// See https://www.sqlite.org/rescode.html#primary_result_code_list.
+const (
+	CodeOK         Code = 0
+	CodeError      Code = 1
+	CodeInternal   Code = 2
+	CodePerm       Code = 3
+	CodeAbort      Code = 4
+	CodeBusy       Code = 5
+	CodeLocked     Code = 6
+	CodeNoMem      Code = 7
+	CodeReadOnly   Code = 8
+	CodeInterrupt  Code = 9
+	CodeIOErr      Code = 10
+	CodeCorrupt    Code = 11
+	CodeNotFound   Code = 12
+	CodeFull       Code = 13
+	CodeCantOpen   Code = 14
+	CodeProtocol   Code = 15
+	CodeEmpty      Code = 16
+	CodeSchema     Code = 17
+	CodeTooBig     Code = 18
+	CodeConstraint Code = 19
+	CodeMismatch   Code = 20
+	CodeMisuse     Code = 21
+	CodeNoLFS      Code = 22
+	CodeAuth       Code = 23
+	CodeFormat     Code = 24
+	CodeRange      Code = 25
+	CodeNotADB     Code = 26
+	CodeNotice     Code = 27
+	CodeWarning    Code = 28
+)
+
+// The extended result codes of SQLite for constraint violations.
+//
+// See https://www.sqlite.org/rescode.html#extended_result_code_list.
+const (
+	CodeConstraintCheck      Code = CodeConstraint | 1<<8
+	CodeConstraintForeignKey Code = CodeConstraint | 3<<8
+	CodeConstraintNotNull    Code = CodeConstraint | 5<<8
+	CodeConstraintPrimaryKey Code = CodeConstraint | 6<<8
+	CodeConstraintUnique     Code = CodeConstraint | 8<<8
+)
+
+// ErrorCode will return the result code of the first SQLite error in the chain of err.
+//
+// The extended result code is returned, if the driver provides it. Use [sqlite.Code.Primary] for the primary code.
+// [sqlite.CodeOK] is returned, if err isn't an error of a SQLite driver.
+//
+// Every error of the chain is passed to the registered [sqlite.DriverAdapter] list. The chain is walked like
+// [errors.As] does, so the errors joined per [errors.Join] are searched as well.
+// The built-in adapters match the error types of the drivers by their package path, so no driver has to be imported:
+//   - "modernc.org/sqlite" *Error with the method Code() int
+//   - "github.com/mattn/go-sqlite3" Error with the fields Code and ExtendedCode
+//   - "github.com/ncruces/go-sqlite3" *Error with the method ExtendedCode(), ErrorCode and ExtendedErrorCode
+func ErrorCode(err error) Code {
+	code, _ := chainErrorCode(registeredAdapters(), err)
+	return code
+}
+
+// chainErrorCode returns the code of the first error in the tree of err, which is recognized by one of adapters.
+func chainErrorCode(adapters []DriverAdapter, err error) (Code, bool) {
+	if err == nil {
+		return CodeOK, false
+	}
+	for _, a := range adapters {
+		if code, ok := a.ErrorCode(err); ok {
+			return code, true
+		}
+	}
+
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		return chainErrorCode(adapters, x.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			if code, ok := chainErrorCode(adapters, err); ok {
+				return code, true
+			}
+		}
+	}
+	return CodeOK, false
+}
+
+// methodCode calls the method Code() int of err.
+func methodCode(err error) (Code, bool) {
+	if coder, ok := err.(interface{ Code() int }); ok {
+		return Code(coder.Code()), true
+	}
+	return CodeOK, false
+}
+
+// extendedCode calls the method ExtendedCode() of err, which returns an integer type.
+func extendedCode(err error) (Code, bool) {
+	m := reflect.ValueOf(err).MethodByName("ExtendedCode")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return CodeOK, false
+	}
+	return intCode(m.Call(nil)[0])
+}
+
+// intCode returns the integer v as code.
+func intCode(v reflect.Value) (Code, bool) {
+	switch {
+	case v.CanInt():
+		return Code(v.Int()), true
+	case v.CanUint():
+		return Code(v.Uint()), true
+	}
+	return CodeOK, false
+}
+
+// fieldCode reads the fields ExtendedCode and Code of the struct v.
+func fieldCode(v reflect.Value) (Code, bool) {
+	if v.Kind() != reflect.Struct {
+		return CodeOK, false
+	}
+	if code, ok := intField(v, "ExtendedCode"); ok && code != 0 {
+		return Code(code), true
+	}
+	if code, ok := intField(v, "Code"); ok {
+		return Code(code), true
+	}
+	return CodeOK, false
+}
+
+func intField(v reflect.Value, name string) (int, bool) {
+	f := v.FieldByName(name)
+	if !f.IsValid() || !f.CanInt() {
+		return 0, false
+	}
+	return int(f.Int()), true
+}
+
+// IsBusy reports whether err is a SQLITE_BUSY error, the database file is locked by another connection.
+func IsBusy(err error) bool {
+	return ErrorCode(err).Primary() == CodeBusy
+}
+
+// IsLocked reports whether err is a SQLITE_LOCKED error, a table is locked by the same connection or
+// another connection of the same shared cache.
+func IsLocked(err error) bool {
+	return ErrorCode(err).Primary() == CodeLocked
+}
+
+// IsConstraintUnique reports whether err is a violated UNIQUE or PRIMARY KEY constraint.
+func IsConstraintUnique(err error) bool {
+	code := ErrorCode(err)
+	return code == CodeConstraintUnique || code == CodeConstraintPrimaryKey
+}
+
+// IsConstraintForeignKey reports whether err is a violated foreign key constraint.
+func IsConstraintForeignKey(err error) bool {
+	return ErrorCode(err) == CodeConstraintForeignKey
+}
+
+// IsReadOnly reports whether err is a SQLITE_READONLY error, e.g. a write on a read-only connection.
+func IsReadOnly(err error) bool {
+	return ErrorCode(err).Primary() == CodeReadOnly
+}
+
+// IsCorrupt reports whether err is a SQLITE_CORRUPT error, the database file is malformed.
+func IsCorrupt(err error) bool {
+	return ErrorCode(err).Primary() == CodeCorrupt
+}
+
+// IsFull reports whether err is a SQLITE_FULL error, the disk is full.
+func IsFull(err error) bool {
+	return ErrorCode(err).Primary() == CodeFull
+}
+
+// isRetryable reports whether a transaction failed with err can be retried.
+func isRetryable(err error) bool {
+	return IsBusy(err) || IsLocked(err)
+}
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// codeError is shaped like the error of "modernc.org/sqlite".
+type codeError int
+
+func (e codeError) Error() string {
+	return fmt.Sprintf("sqlite error %d", int(e))
+}
+
+func (e codeError) Code() int {
+	return int(e)
+}
+
+// fieldError is shaped like the error of "github.com/mattn/go-sqlite3".
+type fieldError struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e fieldError) Error() string {
+	return fmt.Sprintf("sqlite error %d", e.Code)
+}
+
+// extendedError is shaped like the error of "github.com/ncruces/go-sqlite3".
+type extendedError struct {
+	code uint16
+}
+
+type extendedErrorCode uint16
+
+func (e *extendedError) Error() string {
+	return fmt.Sprintf("sqlite error %d", e.code)
+}
+
+func (e *extendedError) ExtendedCode() extendedErrorCode {
+	return extendedErrorCode(e.code)
+}
+
+// shapedErrorAdapter recognizes the errors shaped like the errors of the drivers, so the tests need no driver.
+type shapedErrorAdapter struct {
+	unitTestAdapter
+}
+
+func (shapedErrorAdapter) ErrorCode(err error) (Code, bool) {
+	switch err.(type) {
+	case codeError:
+		return methodCode(err)
+	case fieldError, *fieldError:
+		return fieldCode(reflect.Indirect(reflect.ValueOf(err)))
+	case *extendedError:
+		return extendedCode(err)
+	}
+	return CodeOK, false
+}
+
+func init() {
+	RegisterDriverAdapter(shapedErrorAdapter{unitTestAdapter{driver: "example.com/shaped", driverName: "shaped"}})
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"Nil", nil, CodeOK},
+		{"Unknown", errUnitTest, CodeOK},
+		{"CodeMethod", codeError(CodeBusy), CodeBusy},
+		{"Wrapped", fmt.Errorf("exec: %w", codeError(CodeLocked)), CodeLocked},
+		{"Joined", errors.Join(errUnitTest, fmt.Errorf("exec: %w", codeError(CodeLocked))), CodeLocked},
+		{"JoinedFirst", fmt.Errorf("tx: %w", errors.Join(codeError(CodeBusy), codeError(CodeLocked))), CodeBusy},
+		{"Fields", fieldError{Code: int(CodeBusy)}, CodeBusy},
+		{"ExtendedCodeMethod", &extendedError{code: uint16(CodeConstraintForeignKey)}, CodeConstraintForeignKey},
+		{"ExtendedCode", &fieldError{Code: int(CodeConstraint), ExtendedCode: int(CodeConstraintUnique)}, CodeConstraintUnique},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ErrorCode(tc.err); got != tc.want {
+				t.Fatalf("expected '%d', got '%d'", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBuiltinAdapters_ErrorCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter DriverAdapter
+		err     error
+	}{
+		{"Mattn", mattnAdapter{}, fieldError{Code: int(CodeBusy)}},
+		{"MattnPointer", mattnAdapter{}, &fieldError{Code: int(CodeBusy)}},
+		{"Modernc", moderncAdapter{}, codeError(CodeBusy)},
+		{"Ncruces", ncrucesAdapter{}, &extendedError{code: uint16(CodeBusy)}},
+		{"NcrucesCode", ncrucesAdapter{}, codeError(CodeBusy)},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Errors of other packages with the same fields or methods are not errors of SQLite.
+			if code, ok := tc.adapter.ErrorCode(tc.err); ok {
+				t.Errorf("did not expect code '%d' for '%T'", code, tc.err)
+			}
+		})
+	}
+}
+
+func Test_isDriverError(t *testing.T) {
+	t.Parallel()
+
+	if !isDriverError(codeError(CodeBusy), "github.com/lanz-dev/go-sqlite", "codeError") {
+		t.Error("expected codeError to be an error of this package")
+	}
+	if !isDriverError(&extendedError{}, "github.com/lanz-dev/go-sqlite", "Error", "extendedError") {
+		t.Error("expected *extendedError to be an error of this package")
+	}
+	if isDriverError(codeError(CodeBusy), DriverModernc, "codeError") {
+		t.Error("did not expect codeError to be an error of modernc")
+	}
+	if isDriverError(errUnitTest, "errors", "Error") {
+		t.Error("did not expect an error of another type")
+	}
+}
+
+func TestCode_Primary(t *testing.T) {
+	t.Parallel()
+
+	if got := CodeConstraintForeignKey.Primary(); got != CodeConstraint {
+		t.Errorf("expected '%d', got '%d'", CodeConstraint, got)
+	}
+}
+
+func TestIsFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		is   func(err error) bool
+		err  error
+	}{
+		{"IsBusy", IsBusy, codeError(CodeBusy | 2<<8)},
+		{"IsLocked", IsLocked, codeError(CodeLocked)},
+		{"IsConstraintUnique", IsConstraintUnique, codeError(CodeConstraintUnique)},
+		{"IsConstraintUniquePrimaryKey", IsConstraintUnique, fieldError{Code: int(CodeConstraint), ExtendedCode: int(CodeConstraintPrimaryKey)}},
+		{"IsConstraintForeignKey", IsConstraintForeignKey, codeError(CodeConstraintForeignKey)},
+		{"IsReadOnly", IsReadOnly, codeError(CodeReadOnly)},
+		{"IsCorrupt", IsCorrupt, codeError(CodeCorrupt)},
+		{"IsFull", IsFull, codeError(CodeFull)},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if !tc.is(tc.err) {
+				t.Errorf("expected '%v', got '%v'", true, false)
+			}
+			if tc.is(codeError(CodeConstraintCheck)) || tc.is(errUnitTest) {
+				t.Errorf("expected '%v', got '%v'", false, true)
+			}
+		})
+	}
+}
+
+func Test_isRetryable(t *testing.T) {
+	t.Parallel()
+
+	if !isRetryable(codeError(CodeBusy)) || !isRetryable(codeError(CodeLocked)) {
+		t.Error("expected busy and locked errors to be retryable")
+	}
+	if isRetryable(codeError(CodeConstraint)) {
+		t.Error("expected a constraint error not to be retryable")
+	}
+}
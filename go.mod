@@ -1,5 +1,5 @@
 module github.com/lanz-dev/go-sqlite
 
-go 1.19
+go 1.21
 
 require github.com/DATA-DOG/go-sqlmock v1.5.0
@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"time"
+)
+
+// Hook is called for every query executed per [sql.DB], see [sqlite.WithQueryHook].
+//
+// The queries within transactions and the executions of prepared statements are passed as well,
+// the transactions only to a [sqlite.TxHook]. The BEGIN, COMMIT and ROLLBACK statements of a [sqlite.TxMode] are
+// passed as queries. The pragmas executed on a new connection are not passed.
+type Hook interface {
+	// Before is called before query is executed. The returned context is passed to the driver and to After,
+	// so it can carry e.g. a tracing span.
+	Before(ctx context.Context, query string, args []any) context.Context
+
+	// After is called after query was executed with its duration and error, it's called for every call of Before.
+	// For a query returning rows, dur doesn't include reading the rows.
+	// If err is [driver.ErrSkip], the driver didn't execute query and [database/sql] runs it again
+	// as prepared statement, which calls Before and After again.
+	After(ctx context.Context, query string, args []any, dur time.Duration, err error)
+}
+
+// TxHook is an optional interface of a [sqlite.Hook], which is called for the transactions of the [sql.DB] as well,
+// e.g. for a tracing span per transaction.
+type TxHook interface {
+	// BeforeTx is called before a transaction begins. The returned context is passed to the driver and to AfterTx.
+	BeforeTx(ctx context.Context) context.Context
+
+	// AfterTx is called after the transaction ended per op [sqlite.TxOpCommit] or [sqlite.TxOpRollback] with its error,
+	// or per op [sqlite.TxOpBegin], if the transaction failed to begin.
+	AfterTx(ctx context.Context, op TxOp, err error)
+}
+
+// TxOp is the operation, which ended a transaction passed to [sqlite.TxHook].
+type TxOp string
+
+// The different operations of a transaction.
+const (
+	TxOpBegin    TxOp = "begin"
+	TxOpCommit   TxOp = "commit"
+	TxOpRollback TxOp = "rollback"
+)
+
+// hooks calls all Before in order and all After in reverse order, the same applies to its [sqlite.TxHook].
+type hooks []Hook
+
+func (h hooks) Before(ctx context.Context, query string, args []any) context.Context {
+	for _, hook := range h {
+		ctx = hook.Before(ctx, query, args)
+	}
+	return ctx
+}
+
+func (h hooks) After(ctx context.Context, query string, args []any, dur time.Duration, err error) {
+	for i := len(h) - 1; i >= 0; i-- {
+		h[i].After(ctx, query, args, dur, err)
+	}
+}
+
+func (h hooks) BeforeTx(ctx context.Context) context.Context {
+	for _, hook := range h {
+		if txHook, ok := hook.(TxHook); ok {
+			ctx = txHook.BeforeTx(ctx)
+		}
+	}
+	return ctx
+}
+
+func (h hooks) AfterTx(ctx context.Context, op TxOp, err error) {
+	for i := len(h) - 1; i >= 0; i-- {
+		if txHook, ok := h[i].(TxHook); ok {
+			txHook.AfterTx(ctx, op, err)
+		}
+	}
+}
+
+// buildHook returns nil without hooks, so the connections skip all hook calls.
+func buildHook(h []Hook) Hook {
+	switch len(h) {
+	case 0:
+		return nil
+	case 1:
+		return h[0]
+	}
+	return hooks(h)
+}
+
+// runHook calls fn between Before and After of hook.
+func runHook[T any](ctx context.Context, hook Hook, query string, args []driver.NamedValue, fn func(context.Context) (T, error)) (T, error) {
+	if hook == nil {
+		return fn(ctx)
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	ctx = hook.Before(ctx, query, values)
+	start := time.Now()
+	result, err := fn(ctx)
+	hook.After(ctx, query, values, time.Since(start), err)
+	return result, err
+}
+
+// hookTx passes the end of a transaction to its [sqlite.TxHook].
+type hookTx struct {
+	driver.Tx
+	ctx  context.Context //nolint:containedctx // The context of BeforeTx is passed to AfterTx.
+	hook TxHook
+}
+
+func (t *hookTx) Commit() error {
+	err := t.Tx.Commit()
+	t.hook.AfterTx(t.ctx, TxOpCommit, err)
+	return err
+}
+
+func (t *hookTx) Rollback() error {
+	err := t.Tx.Rollback()
+	t.hook.AfterTx(t.ctx, TxOpRollback, err)
+	return err
+}
+
+// SlogHook is a [sqlite.Hook] logging all queries per [slog.Logger].
+type SlogHook struct {
+	Logger *slog.Logger // Logger used, [slog.Default] if nil
+	Level  slog.Level   // Level of successful queries, failed queries are logged with [slog.LevelError]
+	Args   bool         // Log the arguments of the queries, they can contain sensitive data
+}
+
+// Before returns ctx unchanged.
+func (h SlogHook) Before(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+// After logs query, a query skipped per [driver.ErrSkip] isn't logged.
+func (h SlogHook) After(ctx context.Context, query string, args []any, dur time.Duration, err error) {
+	if err == driver.ErrSkip { //nolint:errorlint // driver.ErrSkip is never wrapped.
+		return
+	}
+	level := h.Level
+	if err != nil {
+		level = slog.LevelError
+	}
+	logQuery(ctx, h.Logger, level, "sqlite query", query, h.Args, args, dur, err)
+}
+
+// SlowQueryHook is a [sqlite.Hook] logging all queries per [slog.Logger], which take at least Threshold.
+type SlowQueryHook struct {
+	Logger    *slog.Logger  // Logger used, [slog.Default] if nil
+	Threshold time.Duration // Minimum duration of a logged query
+	Args      bool          // Log the arguments of the queries, they can contain sensitive data
+}
+
+// Before returns ctx unchanged.
+func (h SlowQueryHook) Before(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+// After logs query, if it took at least Threshold. A query skipped per [driver.ErrSkip] isn't logged.
+func (h SlowQueryHook) After(ctx context.Context, query string, args []any, dur time.Duration, err error) {
+	if dur < h.Threshold || err == driver.ErrSkip { //nolint:errorlint // driver.ErrSkip is never wrapped.
+		return
+	}
+	logQuery(ctx, h.Logger, slog.LevelWarn, "sqlite slow query", query, h.Args, args, dur, err)
+}
+
+func logQuery(ctx context.Context, logger *slog.Logger, level slog.Level, msg, query string, withArgs bool, args []any, dur time.Duration, err error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.Duration("duration", dur),
+	}
+	if withArgs {
+		attrs = append(attrs, slog.Any("args", args))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
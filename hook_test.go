@@ -0,0 +1,304 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type hookCtxKey struct{}
+
+// recordingHook records all calls as "<name> <Before|After> <query>".
+type recordingHook struct {
+	name  string
+	mu    *sync.Mutex
+	calls *[]string
+	errs  *[]error
+}
+
+func newRecordingHook(names ...string) ([]Hook, *[]string, *[]error) {
+	var (
+		mu    sync.Mutex
+		calls []string
+		errs  []error
+	)
+	hooks := make([]Hook, 0, len(names))
+	for _, name := range names {
+		hooks = append(hooks, recordingHook{name: name, mu: &mu, calls: &calls, errs: &errs})
+	}
+	return hooks, &calls, &errs
+}
+
+func (h recordingHook) Before(ctx context.Context, query string, args []any) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s Before %s %v", h.name, query, args))
+	return context.WithValue(ctx, hookCtxKey{}, h.name)
+}
+
+func (h recordingHook) After(ctx context.Context, query string, args []any, _ time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s After %s %v %v", h.name, query, args, ctx.Value(hookCtxKey{})))
+	*h.errs = append(*h.errs, err)
+}
+
+func (h recordingHook) BeforeTx(ctx context.Context) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s BeforeTx", h.name))
+	return context.WithValue(ctx, hookCtxKey{}, h.name)
+}
+
+func (h recordingHook) AfterTx(ctx context.Context, op TxOp, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.calls = append(*h.calls, fmt.Sprintf("%s AfterTx %s %v", h.name, op, ctx.Value(hookCtxKey{})))
+	*h.errs = append(*h.errs, err)
+}
+
+func Test_conn_Hook(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA foreign_keys").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO t").WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT id FROM t").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectPrepare("DELETE FROM t").ExpectExec().WithArgs(2).WillReturnError(errUnitTest)
+
+	hooks, calls, errs := newRecordingHook("first", "second")
+	db := sql.OpenDB(&initConnector{
+		connector: connector,
+		init:      []ConnInitFunc{execInit([]string{"PRAGMA foreign_keys = ON;"})},
+		hook:      buildHook(hooks),
+	})
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO t VALUES (?);", 1); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	var id int
+	if err := db.QueryRow("SELECT id FROM t;").Scan(&id); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	stmt, err := db.Prepare("DELETE FROM t WHERE id = ?;")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer stmt.Close()
+	if _, err := stmt.Exec(2); !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+
+	expected := []string{
+		"first Before INSERT INTO t VALUES (?); [1]",
+		"second Before INSERT INTO t VALUES (?); [1]",
+		"second After INSERT INTO t VALUES (?); [1] second",
+		"first After INSERT INTO t VALUES (?); [1] second",
+		"first Before SELECT id FROM t; []",
+		"second Before SELECT id FROM t; []",
+		"second After SELECT id FROM t; [] second",
+		"first After SELECT id FROM t; [] second",
+		"first Before DELETE FROM t WHERE id = ?; [2]",
+		"second Before DELETE FROM t WHERE id = ?; [2]",
+		"second After DELETE FROM t WHERE id = ?; [2] second",
+		"first After DELETE FROM t WHERE id = ?; [2] second",
+	}
+	if got := strings.Join(*calls, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected '%s', got '%s'", strings.Join(expected, "\n"), got)
+	}
+	if got := *errs; len(got) != 6 || got[0] != nil || !errors.Is(got[5], errUnitTest) {
+		t.Errorf("expected the error only for the prepared statement, got '%v'", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_conn_TxHook(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin().WillReturnError(errUnitTest)
+
+	hooks, calls, errs := newRecordingHook("first", "second")
+	db := sql.OpenDB(&initConnector{connector: connector, hook: buildHook(hooks)})
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Begin(); !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+
+	expected := []string{
+		"first BeforeTx",
+		"second BeforeTx",
+		"second AfterTx commit second",
+		"first AfterTx commit second",
+		"first BeforeTx",
+		"second BeforeTx",
+		"second AfterTx rollback second",
+		"first AfterTx rollback second",
+		"first BeforeTx",
+		"second BeforeTx",
+		"second AfterTx begin second",
+		"first AfterTx begin second",
+	}
+	if got := strings.Join(*calls, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected '%s', got '%s'", strings.Join(expected, "\n"), got)
+	}
+	if got := *errs; len(got) != 6 || got[0] != nil || !errors.Is(got[5], errUnitTest) {
+		t.Errorf("expected the error only for the failed begin, got '%v'", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_runHook_ErrSkip(t *testing.T) {
+	t.Parallel()
+
+	hooks, calls, errs := newRecordingHook("first")
+	_, err := runHook(context.Background(), buildHook(hooks), "INSERT INTO t VALUES (?);", []driver.NamedValue{{Ordinal: 1, Value: 1}},
+		func(context.Context) (driver.Result, error) {
+			return nil, driver.ErrSkip
+		})
+	if !errors.Is(err, driver.ErrSkip) {
+		t.Fatalf("expect error to be '%s', got '%s'", driver.ErrSkip, err)
+	}
+
+	expected := []string{
+		"first Before INSERT INTO t VALUES (?); [1]",
+		"first After INSERT INTO t VALUES (?); [1] first",
+	}
+	if got := strings.Join(*calls, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected '%s', got '%s'", strings.Join(expected, "\n"), got)
+	}
+	if got := *errs; len(got) != 1 || !errors.Is(got[0], driver.ErrSkip) {
+		t.Errorf("expected '%s', got '%v'", driver.ErrSkip, got)
+	}
+}
+
+func Test_conn_NoHook(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectPrepare("DELETE FROM t")
+
+	c, err := (&initConnector{connector: connector}).Connect(context.Background())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	ds, err := c.(*conn).PrepareContext(context.Background(), "DELETE FROM t;")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, ok := ds.(*stmt); ok {
+		t.Error("expected the statement of the driver without a hook")
+	}
+}
+
+func Test_buildHook(t *testing.T) {
+	t.Parallel()
+
+	if got := buildHook(nil); got != nil {
+		t.Errorf("expected '%v', got '%v'", nil, got)
+	}
+	single := SlogHook{}
+	if got := buildHook([]Hook{single}); got != single {
+		t.Errorf("expected '%v', got '%v'", single, got)
+	}
+	if got, ok := buildHook([]Hook{single, single}).(hooks); !ok || len(got) != 2 {
+		t.Errorf("expected '%d' hooks, got '%v'", 2, got)
+	}
+}
+
+func TestSlogHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	hook := SlogHook{Logger: logger, Level: slog.LevelDebug, Args: true}
+
+	ctx := hook.Before(context.Background(), "SELECT ?;", []any{1})
+	hook.After(ctx, "SELECT ?;", []any{1}, time.Millisecond, nil)
+	hook.After(ctx, "SELECT ?;", []any{2}, time.Millisecond, errUnitTest)
+	hook.After(ctx, "SELECT ?;", []any{3}, time.Millisecond, driver.ErrSkip)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected '%d' lines, got '%s'", 2, buf.String())
+	}
+	for _, want := range []string{"level=DEBUG", `msg="sqlite query"`, `query="SELECT ?;"`, "duration=1ms", "args=[1]"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("expected '%s' in '%s'", want, lines[0])
+		}
+	}
+	for _, want := range []string{"level=ERROR", "args=[2]", "error=" + errUnitTest.Error()} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected '%s' in '%s'", want, lines[1])
+		}
+	}
+}
+
+func TestSlogHook_WithoutArgs(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	hook := SlogHook{Logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	hook.After(context.Background(), "SELECT ?;", []any{"secret"}, time.Millisecond, nil)
+
+	if got := buf.String(); !strings.Contains(got, "level=INFO") || strings.Contains(got, "secret") {
+		t.Errorf("expected an info without args, got '%s'", got)
+	}
+}
+
+func TestSlowQueryHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	hook := SlowQueryHook{Logger: slog.New(slog.NewTextHandler(&buf, nil)), Threshold: 100 * time.Millisecond}
+	hook.After(context.Background(), "SELECT 1;", nil, 99*time.Millisecond, nil)
+	hook.After(context.Background(), "SELECT 2;", nil, 100*time.Millisecond, nil)
+	hook.After(context.Background(), "SELECT 3;", nil, time.Second, driver.ErrSkip)
+
+	got := buf.String()
+	if strings.Contains(got, "SELECT 1;") {
+		t.Errorf("did not expect the fast query in '%s'", got)
+	}
+	if strings.Contains(got, "SELECT 3;") {
+		t.Errorf("did not expect the skipped query in '%s'", got)
+	}
+	for _, want := range []string{"level=WARN", `msg="sqlite slow query"`, `query="SELECT 2;"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected '%s' in '%s'", want, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestBackup(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver)
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+		for i := 0; i < 1000; i++ {
+			mustExec(t, db, "INSERT INTO t VALUES (?)", "some text to fill up the pages")
+		}
+
+		dest := tempPath(t, "backup.db")
+		steps := 0
+		err := sqlite.Backup(context.Background(), db, dest, &sqlite.BackupOptions{
+			StepPages: 2,
+			Progress: func(remaining, total int) {
+				steps++
+			},
+		})
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if steps < 2 {
+			t.Errorf("expected multiple steps, got '%d'", steps)
+		}
+
+		backup := connectPath(t, driver, dest)
+		if got := count(t, backup, "t"); got != 1000 {
+			t.Errorf("expected '%d', got '%d'", 1000, got)
+		}
+	})
+}
+
+// wrappedDriver hides the online backup API of the driver connections.
+type wrappedDriver struct {
+	driver.Driver
+}
+
+type wrappedConn struct {
+	driver.Conn
+}
+
+func (d wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrappedConn{conn}, nil
+}
+
+func TestBackup_VacuumInto(t *testing.T) {
+	driverName := "sqlite_wrapped_backup"
+	db, err := sql.Open(sqlite.DriverNameModernc, "")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	sql.Register(driverName, wrappedDriver{db.Driver()})
+	_ = db.Close()
+
+	db, _ = connect(t, sqlite.DriverModernc, sqlite.WithDriverName(driverName))
+	mustExec(t, db, "CREATE TABLE t (v TEXT)")
+	mustExec(t, db, "INSERT INTO t VALUES ('a'), ('b')")
+
+	dest := tempPath(t, "backup.db")
+	if err := sqlite.Backup(context.Background(), db, dest, nil); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	backup := connectPath(t, sqlite.DriverModernc, dest)
+	if got := count(t, backup, "t"); got != 2 {
+		t.Errorf("expected '%d', got '%d'", 2, got)
+	}
+}
@@ -0,0 +1,55 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestCapabilities(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver)
+
+		caps, err := sqlite.Capabilities(context.Background(), db)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if !caps.AtLeast("3.35.0") || !caps.Has("RETURNING") || !caps.Has("JSON1") {
+			t.Errorf("expected SQLite 3.35.0 with RETURNING and JSON1, got '%+v'", caps)
+		}
+		if caps.AtLeast("99.0") {
+			t.Errorf("did not expect SQLite 99.0, got '%s'", caps.Version)
+		}
+
+		// The probed features must match the features usable per SQL.
+		features := map[string]string{
+			"FTS5":      "CREATE VIRTUAL TABLE temp.caps_fts5 USING fts5(v);",
+			"RTREE":     "CREATE VIRTUAL TABLE temp.caps_rtree USING rtree(id, x0, x1);",
+			"MATH":      "SELECT sqrt(4);",
+			"RETURNING": "CREATE TEMP TABLE caps_returning (id INTEGER PRIMARY KEY); INSERT INTO caps_returning DEFAULT VALUES RETURNING id;",
+		}
+		for feature, query := range features {
+			_, err := db.Exec(query)
+			if got := caps.Has(feature); got != (err == nil) {
+				t.Errorf("expected %s '%v', got '%v' with error '%v'", feature, err == nil, got, err)
+			}
+		}
+	})
+}
+
+func TestWithRequire(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		connect(t, driver, sqlite.WithRequire("3.35.0", "JSON1", "THREADSAFE"))
+
+		_, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithPath("file:"+tempPath(t, "data.db")),
+			sqlite.WithRequire("99.0", "FTS5", "NOT_A_FEATURE"),
+		)
+		if !errors.Is(err, sqlite.ErrMissingCapability) {
+			t.Fatalf("expect error to be '%s', got '%s'", sqlite.ErrMissingCapability, err)
+		}
+	})
+}
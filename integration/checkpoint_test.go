@@ -0,0 +1,107 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestCheckpoint(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, path := connect(t, driver, sqlite.WithWALAutoCheckpoint(-1))
+		mustExec(t, db, "CREATE TABLE t (v TEXT);")
+		mustExec(t, db, "INSERT INTO t VALUES ('a');")
+
+		ctx := context.Background()
+		result, err := sqlite.Checkpoint(ctx, db, sqlite.CheckpointPassive)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if result.Busy || result.LogFrames <= 0 || result.Checkpointed != result.LogFrames {
+			t.Errorf("expected all frames to be checkpointed, got '%+v'", result)
+		}
+
+		if _, err := sqlite.Checkpoint(ctx, db, sqlite.CheckpointTruncate); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		info, err := os.Stat(path + "-wal")
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if info.Size() != 0 {
+			t.Errorf("expected '%d', got '%d'", 0, info.Size())
+		}
+	})
+}
+
+func TestShutdown_WithCheckpoint(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, path := connect(t, driver)
+		mustExec(t, db, "CREATE TABLE t (v TEXT);")
+
+		if err := sqlite.Shutdown(db, sqlite.WithShutdownCheckpoint()); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		for _, suffix := range []string{"-wal", "-shm"} {
+			if _, err := os.Stat(path + suffix); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected no '%s' file, got '%v'", suffix, err)
+			}
+		}
+	})
+}
+
+func TestDB_ShutdownDrainsTx(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		path := tempPath(t, "data.db")
+		db, err := sqlite.ConnectPool(sqlite.WithDriver(driver), sqlite.WithPath("file:"+path))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		mustExec(t, db.Writer(), "CREATE TABLE t (v TEXT);")
+
+		ctx := context.Background()
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+
+		shutdown := make(chan error)
+		go func() {
+			shutdown <- db.ShutdownContext(ctx)
+		}()
+
+		// Wait until new operations are rejected, the transaction can still be used.
+		for {
+			rows, err := db.Query("SELECT v FROM t;")
+			if errors.Is(err, sqlite.ErrShutdown) {
+				break
+			}
+			if err == nil {
+				_ = rows.Close()
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if _, err := tx.Exec("INSERT INTO t VALUES ('a');"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if err := <-shutdown; err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+
+		for _, suffix := range []string{"-wal", "-shm"} {
+			if _, err := os.Stat(path + suffix); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected no '%s' file, got '%v'", suffix, err)
+			}
+		}
+		if got := count(t, connectPath(t, driver, path), "t"); got != 1 {
+			t.Errorf("expected '%d', got '%d'", 1, got)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package integration_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestConnInit_EveryConnection(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		var inits int
+		db, _ := connect(t, driver,
+			sqlite.WithDisabledLimits(),
+			sqlite.WithJournalSizeLimit(4242),
+			sqlite.WithConnInit(func(ctx context.Context, conn *sql.Conn) error {
+				inits++
+				_, err := conn.ExecContext(ctx, "CREATE TEMP TABLE scratch (id INTEGER);")
+				return err
+			}),
+		)
+
+		ctx := context.Background()
+		var conns []*sql.Conn
+		for i := 0; i < 3; i++ {
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			conns = append(conns, conn)
+
+			var limit int
+			if err := conn.QueryRowContext(ctx, "PRAGMA journal_size_limit;").Scan(&limit); err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if limit != 4242 {
+				t.Errorf("expected '%d', got '%d'", 4242, limit)
+			}
+			if _, err := conn.ExecContext(ctx, "INSERT INTO scratch VALUES (1);"); err != nil {
+				t.Errorf("did not expect error '%s'", err)
+			}
+		}
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+
+		if inits != 3 {
+			t.Errorf("expected '%d', got '%d'", 3, inits)
+		}
+	})
+}
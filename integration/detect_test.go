@@ -0,0 +1,76 @@
+package integration_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestDetectedDrivers(t *testing.T) {
+	db, err := sql.Open(sqlite.DriverNameMattn, "")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	sql.Register("sqlite3_wrapped_detect", wrappedDriver{db.Driver()})
+	_ = db.Close()
+
+	detected := sqlite.DetectedDrivers()
+	for _, expected := range []sqlite.DetectedDriver{
+		{Driver: sqlite.DriverModernc, DriverName: sqlite.DriverNameModernc},
+		{Driver: sqlite.DriverMattn, DriverName: sqlite.DriverNameMattn},
+		{Driver: sqlite.DriverMattn, DriverName: "sqlite3_wrapped_detect"},
+	} {
+		found := false
+		for _, d := range detected {
+			found = found || d == expected
+		}
+		if !found {
+			t.Errorf("expected '%s' within '%v'", expected, detected)
+		}
+	}
+}
+
+func TestWithDriverPreference(t *testing.T) {
+	for _, driver := range drivers {
+		driver := driver
+		t.Run(string(driver), func(t *testing.T) {
+			t.Parallel()
+
+			db, err := sqlite.Connect(sqlite.WithDriverPreference(driver, sqlite.DriverNcruces))
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			defer db.Close()
+
+			if got := driverOf(db); got != driver {
+				t.Errorf("expected '%s', got '%s'", driver, got)
+			}
+		})
+	}
+}
+
+func TestEnvDriver(t *testing.T) {
+	t.Setenv(sqlite.EnvDriver, string(sqlite.DriverMattn))
+
+	db, err := sqlite.Connect(sqlite.WithDriverPreference(sqlite.DriverModernc))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+
+	if got := driverOf(db); got != sqlite.DriverMattn {
+		t.Errorf("expected '%s', got '%s'", sqlite.DriverMattn, got)
+	}
+}
+
+// driverOf returns the driver of db by the package path of its type.
+func driverOf(db interface{ Driver() driver.Driver }) sqlite.Driver {
+	t := reflect.TypeOf(sqlite.UnwrapDriver(db.Driver()))
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return sqlite.Driver(t.PkgPath())
+}
@@ -0,0 +1,4 @@
+// Package integration runs the tests of "github.com/lanz-dev/go-sqlite" against the real SQLite drivers.
+//
+// It is a separate module, so the drivers don't end up as dependencies of "github.com/lanz-dev/go-sqlite".
+package integration
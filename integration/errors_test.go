@@ -0,0 +1,77 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// errUnrelated is an error of another package, which has a method Code() int like the error of "modernc.org/sqlite".
+type errUnrelated int
+
+func (e errUnrelated) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+func (e errUnrelated) Code() int {
+	return int(e)
+}
+
+func TestErrorCode_Unrelated(t *testing.T) {
+	t.Parallel()
+
+	if err := fmt.Errorf("call: %w", errUnrelated(sqlite.CodeBusy)); sqlite.IsBusy(err) {
+		t.Errorf("did not expect a busy error for '%v'", err)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, path := connect(t, driver, sqlite.WithDisabledLimits(), sqlite.WithBusyTimeout(1))
+		mustExec(t, db, "CREATE TABLE parent (id INTEGER PRIMARY KEY, name TEXT UNIQUE);")
+		mustExec(t, db, "CREATE TABLE child (parent_id INTEGER REFERENCES parent(id));")
+		mustExec(t, db, "INSERT INTO parent VALUES (1, 'a');")
+
+		_, err := db.Exec("INSERT INTO parent VALUES (2, 'a');")
+		if !sqlite.IsConstraintUnique(err) {
+			t.Errorf("expected a unique constraint error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+		}
+		_, err = db.Exec("INSERT INTO parent VALUES (1, 'b');")
+		if !sqlite.IsConstraintUnique(err) {
+			t.Errorf("expected a primary key constraint error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+		}
+		_, err = db.Exec("INSERT INTO child VALUES (42);")
+		if !sqlite.IsConstraintForeignKey(err) {
+			t.Errorf("expected a foreign key constraint error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+		}
+
+		ctx := context.Background()
+		lock, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer lock.Close()
+		if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		_, err = db.Exec("INSERT INTO parent VALUES (3, 'c');")
+		if !sqlite.IsBusy(err) {
+			t.Errorf("expected a busy error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+		}
+		if joined := errors.Join(errUnrelated(sqlite.CodeLocked), err); !sqlite.IsBusy(joined) {
+			t.Errorf("expected a busy error, got '%v' with code '%d'", joined, sqlite.ErrorCode(joined))
+		}
+		if _, err := lock.ExecContext(ctx, "ROLLBACK;"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+
+		readOnly := connectPath(t, driver, path, sqlite.WithQueryOnly(true))
+		_, err = readOnly.Exec("INSERT INTO parent VALUES (4, 'd');")
+		if !sqlite.IsReadOnly(err) {
+			t.Errorf("expected a read-only error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// syncBuffer is a [bytes.Buffer] safe for the concurrent writes of the connections.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestQueryHook(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		var buf syncBuffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		db, _ := connect(t, driver, sqlite.WithQueryHook(sqlite.SlogHook{Logger: logger, Args: true}))
+
+		mustExec(t, db, "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT);")
+		mustExec(t, db, "INSERT INTO t VALUES (?, ?);", 1, "a")
+
+		stmt, err := db.Prepare("INSERT INTO t VALUES (?, ?);")
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if _, err := stmt.Exec(2, "b"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		_ = stmt.Close()
+
+		if err := sqlite.WithTx(context.Background(), db, sqlite.TxOptions{Mode: sqlite.TxImmediate}, func(tx *sql.Tx) error {
+			_, err := tx.Exec("DELETE FROM t WHERE id = ?;", 2)
+			return err
+		}); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if got := count(t, db, "t"); got != 1 {
+			t.Errorf("expected '%d', got '%d'", 1, got)
+		}
+		if _, err := db.Exec("INSERT INTO t VALUES (?, ?);", 1, "c"); err == nil {
+			t.Fatal("expected a unique constraint error")
+		}
+
+		logged := buf.String()
+		for _, want := range []string{
+			`query="CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT);"`,
+			`query="INSERT INTO t VALUES (?, ?);" duration=`,
+			"args=\"[1 a]\"",
+			"args=\"[2 b]\"",
+			`query="BEGIN IMMEDIATE;"`,
+			`query="DELETE FROM t WHERE id = ?;"`,
+			`query="SELECT count(*) FROM t"`,
+			"level=ERROR",
+		} {
+			if !strings.Contains(logged, want) {
+				t.Errorf("expected '%s' in '%s'", want, logged)
+			}
+		}
+		if strings.Contains(logged, "PRAGMA") {
+			t.Errorf("did not expect the pragmas of a new connection in '%s'", logged)
+		}
+	})
+}
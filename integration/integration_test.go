@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	_ "modernc.org/sqlite"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+var drivers = []sqlite.Driver{
+	sqlite.DriverMattn,
+	sqlite.DriverModernc,
+}
+
+// forEachDriver runs fn as parallel subtest for every driver.
+func forEachDriver(t *testing.T, fn func(t *testing.T, driver sqlite.Driver)) {
+	for _, driver := range drivers {
+		driver := driver
+		t.Run(string(driver), func(t *testing.T) {
+			t.Parallel()
+			fn(t, driver)
+		})
+	}
+}
+
+// tempPath returns a path to a database file within a temp dir.
+func tempPath(t *testing.T, name string) string {
+	return filepath.Join(t.TempDir(), name)
+}
+
+// connect opens a database file within a temp dir.
+func connect(t *testing.T, driver sqlite.Driver, opts ...sqlite.Option) (*sql.DB, string) {
+	path := tempPath(t, "data.db")
+	return connectPath(t, driver, path, opts...), path
+}
+
+func connectPath(t *testing.T, driver sqlite.Driver, path string, opts ...sqlite.Option) *sql.DB {
+	return connectWith(t, append([]sqlite.Option{
+		sqlite.WithDriver(driver),
+		sqlite.WithPath("file:" + path),
+	}, opts...)...)
+}
+
+// connectWith opens a database closed on cleanup.
+func connectWith(t *testing.T, opts ...sqlite.Option) *sql.DB {
+	db, err := sqlite.Connect(opts...)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("did not expect error '%s' for '%s'", err, query)
+	}
+}
+
+func count(t *testing.T, db *sql.DB, table string) int {
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	return n
+}
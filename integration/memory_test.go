@@ -0,0 +1,75 @@
+package integration_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+	"github.com/lanz-dev/go-sqlite/sqlitetest"
+)
+
+func TestConnectMemory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		name := t.Name()
+
+		db, drop, err := sqlite.ConnectMemory(name, sqlite.WithDriver(driver), sqlite.WithDisabledLimits())
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		// Without idle connections only the anchor keeps the database alive.
+		db.SetMaxIdleConns(0)
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+		mustExec(t, db, "INSERT INTO t VALUES ('a')")
+
+		other, dropOther, err := sqlite.ConnectMemory(name, sqlite.WithDriver(driver))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if got := count(t, other, "t"); got != 1 {
+			t.Errorf("expected '%d', got '%d'", 1, got)
+		}
+		if err := dropOther(); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if err := drop(); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+
+		db, drop, err = sqlite.ConnectMemory(name, sqlite.WithDriver(driver))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer func() {
+			_ = drop()
+		}()
+		if _, err := db.Exec("SELECT * FROM t"); err == nil {
+			t.Error("expected the dropped database to be empty")
+		}
+	})
+}
+
+func TestNewDB(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db := sqlitetest.NewDB(t, sqlite.WithDriver(driver))
+		mustExec(t, db, "CREATE TABLE t (v INTEGER)")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if _, err := db.Exec("INSERT INTO t VALUES (?)", i); err != nil {
+					t.Errorf("did not expect error '%s'", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if got := count(t, db, "t"); got != 10 {
+			t.Errorf("expected '%d', got '%d'", 10, got)
+		}
+		if other := sqlitetest.NewDB(t, sqlite.WithDriver(driver)); other == db {
+			t.Error("expected a new database")
+		}
+	})
+}
@@ -0,0 +1,4 @@
+// Package ncruces runs the tests of "github.com/lanz-dev/go-sqlite" against "github.com/ncruces/go-sqlite3".
+//
+// The driver registers the same name as "github.com/mattn/go-sqlite3", so it needs its own test binary.
+package ncruces
@@ -0,0 +1,222 @@
+package ncruces_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/vfs/memdb"
+
+	"github.com/lanz-dev/go-sqlite"
+	"github.com/lanz-dev/go-sqlite/sqlitetest"
+)
+
+func connect(t *testing.T, opts ...sqlite.Option) *sql.DB {
+	db, err := sqlite.Connect(append([]sqlite.Option{
+		sqlite.WithPath("file:" + filepath.Join(t.TempDir(), "data.db")),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func TestDetectDriver(t *testing.T) {
+	db := connect(t)
+
+	config, err := sqlite.InspectPragmas(t.Context(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.JournalMode != sqlite.JournalWAL {
+		t.Errorf("expected '%s', got '%s'", sqlite.JournalWAL, config.JournalMode)
+	}
+	if config.BusyTimeout != 4000 {
+		t.Errorf("expected '%d', got '%d'", 4000, config.BusyTimeout)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	db, err := sqlite.Connect(sqlite.WithDriver(sqlite.DriverNcruces))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+
+	config, err := sqlite.InspectPragmas(t.Context(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.BusyTimeout != 4000 || !config.ForeignKey || config.SyncMode != sqlite.SyncNormal {
+		t.Errorf("expected the default pragmas, got '%+v'", config)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected an in-memory database, got the file '%s'", entries[0].Name())
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	db, err := sqlite.Connect(
+		sqlite.WithDriver(sqlite.DriverNcruces),
+		sqlite.WithPath("data.db"),
+		sqlite.WithStrictPragmas(),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "data.db")); err != nil {
+		t.Errorf("did not expect error '%s'", err)
+	}
+}
+
+func TestDetectedDrivers(t *testing.T) {
+	expected := []sqlite.DetectedDriver{{Driver: sqlite.DriverNcruces, DriverName: sqlite.DriverNameNcruces}}
+	got := sqlite.DetectedDrivers()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+}
+
+func TestStrictPragmas(t *testing.T) {
+	connect(t,
+		sqlite.WithDriver(sqlite.DriverNcruces),
+		sqlite.WithStrictPragmas(),
+		sqlite.WithCacheSize(-4000),
+		sqlite.WithLockingMode(sqlite.LockingNormal),
+		sqlite.WithPageSize(8192),
+		sqlite.WithSecureDelete(sqlite.SecureDeleteOn),
+		sqlite.WithTempStore(sqlite.TempStoreMemory),
+		sqlite.WithTxLock(sqlite.TxImmediate),
+		sqlite.WithWALAutoCheckpoint(500),
+	)
+}
+
+func TestErrorCode(t *testing.T) {
+	db := connect(t, sqlite.WithDriver(sqlite.DriverNcruces))
+	if _, err := db.Exec("CREATE TABLE parent (id INTEGER PRIMARY KEY, name TEXT UNIQUE);"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Exec("CREATE TABLE child (parent_id INTEGER REFERENCES parent(id));"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Exec("INSERT INTO parent VALUES (1, 'a');"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	_, err := db.Exec("INSERT INTO parent VALUES (2, 'a');")
+	if !sqlite.IsConstraintUnique(err) {
+		t.Errorf("expected a unique constraint error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+	}
+	_, err = db.Exec("INSERT INTO child VALUES (42);")
+	if !sqlite.IsConstraintForeignKey(err) {
+		t.Errorf("expected a foreign key constraint error, got '%v' with code '%d'", err, sqlite.ErrorCode(err))
+	}
+}
+
+func TestNewDB(t *testing.T) {
+	db := sqlitetest.NewDB(t, sqlite.WithDisabledLimits())
+	db.SetMaxIdleConns(0)
+	if _, err := db.Exec("CREATE TABLE t (v TEXT);"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Exec("INSERT INTO t VALUES ('a');"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+}
+
+func TestWithPreset_Test(t *testing.T) {
+	db, err := sqlite.Connect(sqlite.WithPreset(sqlite.PresetTest), sqlite.WithStrictPragmas())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (v TEXT);"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Exec("INSERT INTO t VALUES ('a');"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	db := sqlitetest.New(t)
+	sqlitetest.LoadFixtures(t, db, fstest.MapFS{
+		"1_schema.sql": {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"users.json":   {Data: []byte(`[{"id": 1, "name": "Alice"}]`)},
+	})
+	snapshot := sqlitetest.Snapshot(t, db)
+
+	if _, err := db.Exec("DELETE FROM users;"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	snapshot.Restore(t)
+
+	var name string
+	if err := db.QueryRow("SELECT name FROM users WHERE id = 1;").Scan(&name); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if name != "Alice" {
+		t.Errorf("expected '%s', got '%s'", "Alice", name)
+	}
+}
+
+func TestQueryHook(t *testing.T) {
+	var buf bytes.Buffer
+	db := connect(t, sqlite.WithQueryHook(sqlite.SlogHook{Logger: slog.New(slog.NewTextHandler(&buf, nil)), Args: true}))
+
+	if _, err := db.Exec("CREATE TABLE t (v TEXT);"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	stmt, err := db.Prepare("INSERT INTO t VALUES (?);")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer stmt.Close()
+	if _, err := stmt.Exec("a"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	logged := buf.String()
+	for _, want := range []string{`query="CREATE TABLE t (v TEXT);"`, `query="INSERT INTO t VALUES (?);"`, "args=[a]"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("expected '%s' in '%s'", want, logged)
+		}
+	}
+	if strings.Contains(logged, "PRAGMA") {
+		t.Errorf("did not expect the pragmas of a new connection in '%s'", logged)
+	}
+}
+
+func TestStats(t *testing.T) {
+	db := sqlitetest.NewDB(t)
+	stats, err := sqlite.Stats(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if stats.File != "" || stats.PageSize <= 0 {
+		t.Errorf("expected an in-memory database, got '%+v'", stats)
+	}
+}
@@ -0,0 +1,141 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestPath_Memory(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	for _, driver := range drivers {
+		db, err := sqlite.Connect(sqlite.WithDriver(driver))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+		_ = db.Close()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("expected an in-memory database, got the file '%s'", entries[0].Name())
+	}
+}
+
+func TestPath_CreateDirs(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		path := filepath.Join(t.TempDir(), "a b", "c?#%", "data.db")
+
+		db, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithURI(sqlite.FilePath(path).CreateDirs()),
+		)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+		_ = db.Close()
+
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected the database file '%s', got '%s'", path, err)
+		}
+	})
+}
+
+func TestPath_Plain(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		path := filepath.Join(t.TempDir(), "data")
+
+		db, err := sqlite.Connect(sqlite.WithDriver(driver), sqlite.WithPath(path))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+		_ = db.Close()
+
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected the database file '%s', got '%s'", path, err)
+		}
+	})
+}
+
+func TestPath_ReadOnly(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, path := connect(t, driver)
+		mustExec(t, db, "CREATE TABLE t (v TEXT)")
+
+		readOnly, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithURI(sqlite.FilePath(path).ReadOnly()),
+			sqlite.WithJournalMode(sqlite.JournalDefault),
+			sqlite.WithJournalSizeLimit(0),
+		)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer readOnly.Close()
+
+		_, err = readOnly.Exec("INSERT INTO t VALUES ('a')")
+		if !sqlite.IsReadOnly(err) {
+			t.Errorf("expected a read-only error, got '%v'", err)
+		}
+	})
+}
+
+func TestPath_SharedMemory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithURI(sqlite.SharedMemory(t.Name())),
+			sqlite.WithDisabledLimits(),
+		)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(2)
+
+		ctx := context.Background()
+		conn1, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer conn1.Close()
+		conn2, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer conn2.Close()
+
+		if _, err := conn1.ExecContext(ctx, "CREATE TABLE t (v TEXT)"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if _, err := conn2.ExecContext(ctx, "INSERT INTO t VALUES ('a')"); err != nil {
+			t.Fatalf("expected the table of the other connection, got '%s'", err)
+		}
+		_ = conn1.Close()
+		_ = conn2.Close()
+
+		if got := count(t, db, "t"); got != 1 {
+			t.Errorf("expected '%d', got '%d'", 1, got)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestStrictPragmas(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		connect(t, driver,
+			sqlite.WithStrictPragmas(),
+			sqlite.WithAutoVacuumMode(sqlite.AutoVacuumIncremental),
+			sqlite.WithForeignKeySupport(true),
+			sqlite.WithDeferredForeignKeys(true),
+			sqlite.WithSyncMode(sqlite.SyncFull),
+		)
+	})
+}
+
+func TestStrictPragmas_InMemory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		_, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithPath("file:strict.db?mode=memory"),
+			sqlite.WithStrictPragmas(),
+		)
+
+		var mismatchErr *sqlite.PragmaMismatchError
+		if !errors.As(err, &mismatchErr) {
+			t.Fatalf("expect error to be '%T', got '%s'", mismatchErr, err)
+		}
+		if mismatchErr.Mismatches[0].Pragma != "journal_mode" {
+			t.Errorf("expected '%s', got '%s'", "journal_mode", mismatchErr.Mismatches[0].Pragma)
+		}
+	})
+}
+
+func TestInspectPragmas(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver, sqlite.WithQueryOnly(true))
+
+		config, err := sqlite.InspectPragmas(context.Background(), db)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if config.JournalMode != sqlite.JournalWAL {
+			t.Errorf("expected '%s', got '%s'", sqlite.JournalWAL, config.JournalMode)
+		}
+		if config.SyncMode != sqlite.SyncNormal {
+			t.Errorf("expected '%s', got '%s'", sqlite.SyncNormal, config.SyncMode)
+		}
+		if config.BusyTimeout != 4000 || !config.ForeignKey || !config.QueryOnly {
+			t.Errorf("unexpected config '%+v'", config)
+		}
+	})
+}
+
+func TestExtendedPragmas(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver,
+			sqlite.WithStrictPragmas(),
+			sqlite.WithCacheSize(-4000),
+			sqlite.WithCellSizeCheck(true),
+			sqlite.WithLockingMode(sqlite.LockingNormal),
+			sqlite.WithMmapSize(1<<20),
+			sqlite.WithPageSize(8192),
+			sqlite.WithRecursiveTriggers(true),
+			sqlite.WithSecureDelete(sqlite.SecureDeleteFast),
+			sqlite.WithTempStore(sqlite.TempStoreMemory),
+			sqlite.WithWALAutoCheckpoint(500),
+		)
+
+		config, err := sqlite.InspectPragmas(context.Background(), db)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if config.PageSize != 8192 {
+			t.Errorf("expected '%d', got '%d'", 8192, config.PageSize)
+		}
+		if config.SecureDelete != sqlite.SecureDeleteFast {
+			t.Errorf("expected '%s', got '%s'", sqlite.SecureDeleteFast, config.SecureDelete)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestWithPreset(t *testing.T) {
+	tests := []struct {
+		preset  sqlite.Preset
+		pragmas map[string]string
+	}{
+		{sqlite.PresetServer, map[string]string{
+			"busy_timeout":       "4000",
+			"foreign_keys":       "1",
+			"journal_mode":       "wal",
+			"journal_size_limit": "100000000",
+			"synchronous":        "1",
+		}},
+		{sqlite.PresetBulkImport, map[string]string{
+			"busy_timeout": "4000",
+			"cache_size":   "-262144",
+			"foreign_keys": "1",
+			"journal_mode": "off",
+			"locking_mode": "exclusive",
+			"synchronous":  "0",
+			"temp_store":   "2",
+		}},
+		{sqlite.PresetReadOnlyAnalytics, map[string]string{
+			"busy_timeout": "4000",
+			"cache_size":   "-65536",
+			"foreign_keys": "1",
+			"mmap_size":    "268435456",
+			"query_only":   "1",
+			"temp_store":   "2",
+		}},
+		{sqlite.PresetEmbeddedDevice, map[string]string{
+			"auto_vacuum":        "2",
+			"busy_timeout":       "4000",
+			"cache_size":         "-1024",
+			"foreign_keys":       "1",
+			"journal_mode":       "wal",
+			"journal_size_limit": "4194304",
+			"synchronous":        "2",
+		}},
+		{sqlite.PresetTest, map[string]string{
+			"busy_timeout": "4000",
+			"foreign_keys": "1",
+			"journal_mode": "memory",
+			"synchronous":  "0",
+		}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(string(tc.preset), func(t *testing.T) {
+			forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+				path := tempPath(t, "data.db")
+				if tc.preset == sqlite.PresetReadOnlyAnalytics {
+					// The read-only database must exist.
+					_ = connectPath(t, driver, path, sqlite.WithJournalMode(sqlite.JournalDelete)).Close()
+				}
+
+				opts := []sqlite.Option{sqlite.WithDriver(driver)}
+				if tc.preset != sqlite.PresetTest {
+					// The test preset opens a new in-memory database instead of the file.
+					opts = append(opts, sqlite.WithPath("file:"+path))
+				}
+				db := connectWith(t, append(opts, sqlite.WithPreset(tc.preset), sqlite.WithStrictPragmas())...)
+				for pragma, expected := range tc.pragmas {
+					var got string
+					if err := db.QueryRow("PRAGMA " + pragma).Scan(&got); err != nil {
+						t.Fatalf("did not expect error '%s'", err)
+					}
+					if got != expected {
+						t.Errorf("expected %s '%s', got '%s'", pragma, expected, got)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestWithPreset_TestShared(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, err := sqlite.Connect(
+			sqlite.WithDriver(driver),
+			sqlite.WithPreset(sqlite.PresetTest),
+			sqlite.WithDisabledLimits(),
+		)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		other, err := sqlite.Connect(sqlite.WithDriver(driver), sqlite.WithPreset(sqlite.PresetTest))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		t.Cleanup(func() {
+			_ = other.Close()
+		})
+
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer conn.Close()
+		if _, err := conn.ExecContext(context.Background(), "CREATE TABLE t (id INTEGER PRIMARY KEY); INSERT INTO t VALUES (1);"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+
+		// conn is in use, so another connection of db reads the shared database.
+		if got := count(t, db, "t"); got != 1 {
+			t.Errorf("expected '%d', got '%d'", 1, got)
+		}
+		if err := other.QueryRow("SELECT count(*) FROM t").Scan(new(int)); err == nil {
+			t.Errorf("expected a new database for every connect")
+		}
+	})
+}
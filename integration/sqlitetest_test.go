@@ -0,0 +1,59 @@
+package integration_test
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lanz-dev/go-sqlite"
+	"github.com/lanz-dev/go-sqlite/sqlitetest"
+)
+
+var fixtures = fstest.MapFS{
+	"1_schema.sql": {Data: []byte(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT);
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users(id), items TEXT);
+	`)},
+	// The orders are loaded before their users, the foreign keys are checked on commit.
+	"orders.json": {Data: []byte(`[
+		{"id": 1, "user_id": 2, "items": ["a", "b"]},
+		{"id": 2, "user_id": 1, "items": null}
+	]`)},
+	"users.csv": {Data: []byte("id,name,email\n2,Bob,\n1,Alice,alice@example.com\n")},
+}
+
+func TestSqlitetest_Fixtures(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db := sqlitetest.New(t, sqlite.WithDriver(driver))
+		sqlitetest.LoadFixtures(t, db, fixtures)
+
+		sqlitetest.Golden(t, db, "users", "testdata/users.golden.csv")
+		sqlitetest.Golden(t, db, "orders", "testdata/orders.golden.csv")
+	})
+}
+
+func TestSqlitetest_Snapshot(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		for name, db := range map[string]*sql.DB{
+			"File":   sqlitetest.New(t, sqlite.WithDriver(driver)),
+			"Memory": sqlitetest.NewDB(t, sqlite.WithDriver(driver)),
+		} {
+			db := db
+			t.Run(name, func(t *testing.T) {
+				sqlitetest.LoadFixtures(t, db, fixtures)
+				snapshot := sqlitetest.Snapshot(t, db)
+
+				mustExec(t, db, "DELETE FROM orders")
+				mustExec(t, db, "INSERT INTO users (name) VALUES ('Carol')")
+				snapshot.Restore(t)
+
+				if got := count(t, db, "orders"); got != 2 {
+					t.Errorf("expected '%d', got '%d'", 2, got)
+				}
+				if got := count(t, db, "users"); got != 2 {
+					t.Errorf("expected '%d', got '%d'", 2, got)
+				}
+			})
+		}
+	})
+}
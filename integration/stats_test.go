@@ -0,0 +1,55 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestStats(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, path := connect(t, driver)
+		mustExec(t, db, "CREATE TABLE t (v TEXT);")
+		mustExec(t, db, "INSERT INTO t VALUES ('a');")
+
+		ctx := context.Background()
+		if err := sqlite.OptimizeContext(ctx, db); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		stats, err := sqlite.Stats(ctx, db)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if stats.File != path {
+			t.Errorf("expected '%s', got '%s'", path, stats.File)
+		}
+		if stats.JournalMode != sqlite.JournalWAL {
+			t.Errorf("expected '%s', got '%s'", sqlite.JournalWAL, stats.JournalMode)
+		}
+		if stats.Size() <= 0 || stats.WALSize <= 0 {
+			t.Errorf("expected a database and WAL size, got '%+v'", stats)
+		}
+		if stats.Optimizes != 1 || stats.Pool.OpenConnections != 1 {
+			t.Errorf("expected one optimize and one open connection, got '%+v'", stats)
+		}
+	})
+}
+
+func TestStats_Memory(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, drop, err := sqlite.ConnectMemory(t.Name(), sqlite.WithDriver(driver))
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer drop() //nolint:errcheck
+
+		stats, err := sqlite.Stats(context.Background(), db)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if stats.File != "" || stats.WALSize != 0 {
+			t.Errorf("expected no file, got '%+v'", stats)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package integration_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+func TestWithTx_Concurrent(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver,
+			sqlite.WithDisabledLimits(),
+			sqlite.WithBusyTimeout(1),
+		)
+		mustExec(t, db, "CREATE TABLE counter (n INTEGER);")
+		mustExec(t, db, "INSERT INTO counter VALUES (0);")
+
+		ctx := context.Background()
+		opts := sqlite.TxOptions{Mode: sqlite.TxImmediate, MaxRetries: 1000, Backoff: time.Millisecond}
+
+		const writers = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- sqlite.WithTx(ctx, db, opts, func(tx *sql.Tx) error {
+					var n int
+					if err := tx.QueryRow("SELECT n FROM counter;").Scan(&n); err != nil {
+						return err
+					}
+					time.Sleep(time.Millisecond)
+					_, err := tx.Exec("UPDATE counter SET n = ?;", n+1)
+					return err
+				})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("did not expect error '%s'", err)
+			}
+		}
+		var n int
+		if err := db.QueryRow("SELECT n FROM counter;").Scan(&n); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if n != writers {
+			t.Errorf("expected '%d', got '%d'", writers, n)
+		}
+	})
+}
+
+func TestWithTx_BusyWithoutRetries(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver,
+			sqlite.WithDisabledLimits(),
+			sqlite.WithBusyTimeout(1),
+		)
+
+		ctx := context.Background()
+		lock, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer lock.Close()
+		if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer func() {
+			_, _ = lock.ExecContext(ctx, "ROLLBACK;")
+		}()
+
+		err = sqlite.WithTx(ctx, db, sqlite.TxOptions{Mode: sqlite.TxImmediate}, func(tx *sql.Tx) error {
+			return nil
+		})
+		if err == nil {
+			t.Fatal("expected a busy error")
+		}
+	})
+}
+
+func TestWithTxLock(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, driver sqlite.Driver) {
+		db, _ := connect(t, driver,
+			sqlite.WithDisabledLimits(),
+			sqlite.WithBusyTimeout(1),
+			sqlite.WithTxLock(sqlite.TxImmediate),
+		)
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		// A second immediate transaction can't get the write lock.
+		if _, err := db.Begin(); err == nil {
+			t.Fatal("expected a busy error")
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaintenanceTask is a task run periodically by the [sqlite.Maintainer].
+type MaintenanceTask string
+
+// The different maintenance tasks.
+const (
+	MaintenanceOptimize          MaintenanceTask = "optimize"
+	MaintenanceCheckpoint        MaintenanceTask = "checkpoint"
+	MaintenanceIncrementalVacuum MaintenanceTask = "incremental_vacuum"
+)
+
+// MaintenanceConfig for the [sqlite.Maintainer].
+//
+// An interval of 0 disables the task.
+type MaintenanceConfig struct {
+	OptimizeInterval          time.Duration // Interval for [sqlite.OptimizeContext]
+	CheckpointInterval        time.Duration // Interval for `PRAGMA wal_checkpoint(TRUNCATE)`
+	IncrementalVacuumInterval time.Duration // Interval for `PRAGMA incremental_vacuum(N)`
+	IncrementalVacuumPages    int           // N for `PRAGMA incremental_vacuum(N)`, 0 frees all pages
+	Jitter                    time.Duration // Max random duration added to every interval
+
+	// OnError is called if a task fails.
+	OnError func(task MaintenanceTask, err error)
+}
+
+// Maintainer runs maintenance tasks periodically in the background.
+type Maintainer struct {
+	db       *sql.DB
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+var (
+	maintainersMu sync.Mutex
+	maintainers   = map[*sql.DB]*Maintainer{}
+)
+
+// StartMaintenance will start a [sqlite.Maintainer] for db, which runs until ctx is done or Stop is called.
+//
+// The incremental vacuum task should only be enabled if the database uses [sqlite.AutoVacuumIncremental].
+// [sqlite.ShutdownContext] will stop the [sqlite.Maintainer] of db before the final optimize,
+// [sql.DB.Close] stops it for a db of [sqlite.Connect].
+//
+// See https://www.sqlite.org/pragma.html#pragma_optimize,
+// https://www.sqlite.org/pragma.html#pragma_wal_checkpoint and
+// https://www.sqlite.org/pragma.html#pragma_incremental_vacuum.
+func StartMaintenance(ctx context.Context, db *sql.DB, cfg MaintenanceConfig) *Maintainer {
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Maintainer{
+		db:     db,
+		cancel: cancel,
+	}
+
+	m.start(ctx, MaintenanceOptimize, cfg.OptimizeInterval, cfg, func(ctx context.Context) error {
+		return OptimizeContext(ctx, db)
+	})
+	m.start(ctx, MaintenanceCheckpoint, cfg.CheckpointInterval, cfg, func(ctx context.Context) error {
+		_, err := Checkpoint(ctx, db, CheckpointTruncate)
+		return err
+	})
+	m.start(ctx, MaintenanceIncrementalVacuum, cfg.IncrementalVacuumInterval, cfg, func(ctx context.Context) error {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d);", cfg.IncrementalVacuumPages)); err != nil {
+			return err
+		}
+		countDBMetric(db, func(m *dbMetrics) *atomic.Int64 { return &m.incrementalVacuums })
+		return nil
+	})
+
+	maintainersMu.Lock()
+	previous := maintainers[db]
+	maintainers[db] = m
+	maintainersMu.Unlock()
+	context.AfterFunc(ctx, m.unregister)
+
+	if previous != nil {
+		previous.Stop()
+	}
+
+	return m
+}
+
+func (m *Maintainer) start(ctx context.Context, task MaintenanceTask, interval time.Duration, cfg MaintenanceConfig, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		for {
+			timer := time.NewTimer(interval + jitter(cfg.Jitter))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if err := fn(ctx); err != nil && ctx.Err() == nil && cfg.OnError != nil {
+				cfg.OnError(task, err)
+			}
+		}
+	}()
+}
+
+func jitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
+// Stop will stop all tasks and wait until running tasks are finished.
+func (m *Maintainer) Stop() {
+	m.stopOnce.Do(func() {
+		m.cancel()
+		m.wg.Wait()
+		m.unregister()
+	})
+}
+
+// unregister removes m from the maintainers, unless it was replaced by a newer [sqlite.Maintainer] of db.
+func (m *Maintainer) unregister() {
+	maintainersMu.Lock()
+	if maintainers[m.db] == m {
+		delete(maintainers, m.db)
+	}
+	maintainersMu.Unlock()
+}
+
+func stopMaintenance(db *sql.DB) {
+	maintainersMu.Lock()
+	m, ok := maintainers[db]
+	maintainersMu.Unlock()
+
+	if ok {
+		m.Stop()
+	}
+}
+
+// buildMaintenanceConfig disables the incremental vacuum, if it isn't configured for the connection.
+func buildMaintenanceConfig(config *Config) MaintenanceConfig {
+	cfg := *config.Maintenance
+	if config.AutoVacuumMode != AutoVacuumIncremental {
+		cfg.IncrementalVacuumInterval = 0
+	}
+	return cfg
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func waitForExpectations(t *testing.T, mock sqlmock.Sqlmock) {
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := mock.ExpectationsWereMet()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("there were unfulfilled expectations: %s", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStartMaintenance(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 0, 0))
+	mock.ExpectExec(`PRAGMA incremental_vacuum\(10\);`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := StartMaintenance(context.Background(), db, MaintenanceConfig{
+		OptimizeInterval:          time.Millisecond,
+		CheckpointInterval:        2 * time.Millisecond,
+		IncrementalVacuumInterval: 3 * time.Millisecond,
+		IncrementalVacuumPages:    10,
+		Jitter:                    time.Millisecond,
+	})
+	waitForExpectations(t, mock)
+	m.Stop()
+}
+
+func TestStartMaintenance_OnError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectExec("PRAGMA optimize;").WillReturnError(errUnitTest)
+
+	type taskErr struct {
+		task MaintenanceTask
+		err  error
+	}
+	errs := make(chan taskErr, 10)
+	m := StartMaintenance(context.Background(), db, MaintenanceConfig{
+		OptimizeInterval: time.Millisecond,
+		OnError: func(task MaintenanceTask, err error) {
+			select {
+			case errs <- taskErr{task, err}:
+			default:
+			}
+		},
+	})
+	defer m.Stop()
+
+	select {
+	case got := <-errs:
+		if got.task != MaintenanceOptimize {
+			t.Errorf("expected '%s', got '%s'", MaintenanceOptimize, got.task)
+		}
+		if !errors.Is(got.err, errUnitTest) {
+			t.Errorf("expect error to be '%s', got '%s'", errUnitTest, got.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected OnError to be called")
+	}
+}
+
+func TestMaintainer_StopByShutdown(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	StartMaintenance(context.Background(), db, MaintenanceConfig{
+		OptimizeInterval: time.Hour,
+	})
+	if err := ShutdownContext(context.Background(), db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if maintained(db) {
+		t.Fatal("expected maintainer to be stopped")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func maintained(db *sql.DB) bool {
+	maintainersMu.Lock()
+	defer maintainersMu.Unlock()
+	_, ok := maintainers[db]
+	return ok
+}
+
+func TestMaintainer_StopByContext(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	StartMaintenance(ctx, db, MaintenanceConfig{OptimizeInterval: time.Hour})
+	if !maintained(db) {
+		t.Fatal("expected maintainer to be registered")
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for maintained(db) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected maintainer to be removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaintainer_StopByClose(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	db, err := connect(func(_, _ string) (driver.Connector, error) {
+		return connector, nil
+	}, WithDriver(DriverModernc), WithMaintenance(MaintenanceConfig{OptimizeInterval: time.Hour}))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if !maintained(db) {
+		t.Fatal("expected maintainer to be registered")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if maintained(db) {
+		t.Fatal("expected maintainer to be stopped")
+	}
+}
+
+func Test_buildMaintenanceConfig(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(config, WithMaintenance(MaintenanceConfig{
+		IncrementalVacuumInterval: time.Hour,
+	}))
+	if got := buildMaintenanceConfig(config).IncrementalVacuumInterval; got != 0 {
+		t.Errorf("expected '%s', got '%s'", time.Duration(0), got)
+	}
+
+	optionRunner(config, WithAutoVacuumMode(AutoVacuumIncremental))
+	if got := buildMaintenanceConfig(config).IncrementalVacuumInterval; got != time.Hour {
+		t.Errorf("expected '%s', got '%s'", time.Hour, got)
+	}
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// MemoryAdapter is implemented by a [sqlite.DriverAdapter], which needs another URI for a named in-memory database
+// than [sqlite.SharedMemory]. [sqlite.Connect] opens a path of [sqlite.SharedMemory] per MemoryPath.
+type MemoryAdapter interface {
+	// MemoryPath returns the path of the in-memory database name, shared by all connections of the process.
+	MemoryPath(name string) Path
+}
+
+// ConnectMemory will connect to the in-memory database name, which is shared by all connections of the process.
+//
+// The database is kept alive by an anchor connection, until the returned drop func is called.
+// drop closes the returned [sql.DB] and the anchor connection, which destroys the database.
+//
+// The path is built for the [sqlite.Driver]:
+//   - "file:<name>?mode=memory&cache=shared" for [sqlite.DriverMattn] and [sqlite.DriverModernc]
+//   - "file:/<name>?vfs=memdb" for [sqlite.DriverNcruces], which needs the import of
+//     "github.com/ncruces/go-sqlite3/vfs/memdb"
+//
+// The same options and defaults as for [sqlite.Connect] are used, but the path is always replaced.
+// Use [sqlite.WithDisabledLimits] to use the database from multiple connections of the returned [sql.DB].
+func ConnectMemory(name string, opts ...Option) (db *sql.DB, drop func() error, err error) {
+	return connectMemory(openFunc, name, opts...)
+}
+
+func connectMemory(openFunc sqlOpenFunc, name string, opts ...Option) (*sql.DB, func() error, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("given empty name for an in-memory database, %w", ErrInvalidPath)
+	}
+
+	config, err := buildConfig(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	adapter, ok := lookupDriverAdapter(config.Driver)
+	if !ok {
+		return nil, nil, fmt.Errorf("given '%s', %w", config.Driver, ErrUnknownDriver)
+	}
+	config.Path = memoryPath(adapter, name).String()
+	config.CreateDirs = false
+	config.DSN = adapter.BuildDSN(config)
+
+	anchor, anchorConn, err := openAnchor(openFunc, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := openConfig(openFunc, config)
+	if err != nil {
+		_ = anchorConn.Close()
+		_ = anchor.Close()
+		return nil, nil, err
+	}
+
+	drop := func() error {
+		err := db.Close()
+		if connErr := anchorConn.Close(); err == nil {
+			err = connErr
+		}
+		if anchorErr := anchor.Close(); err == nil {
+			err = anchorErr
+		}
+		return err
+	}
+	return db, drop, nil
+}
+
+// openAnchor opens the connection, which keeps the in-memory database of config alive.
+func openAnchor(openFunc sqlOpenFunc, config *Config) (*sql.DB, *sql.Conn, error) {
+	anchorConfig := *config
+	anchorConfig.Maintenance = nil
+	anchorConfig.Migrations = nil
+	anchorConfig.StrictPragmas = false
+	anchorConfig.Require = nil
+	anchorConfig.ConnectorWrappers = nil
+
+	anchor, err := openDB(openFunc, &anchorConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := anchor.Conn(context.Background())
+	if err != nil {
+		_ = anchor.Close()
+		return nil, nil, err
+	}
+	return anchor, conn, nil
+}
+
+// adapterMemoryPath returns the path of a [sqlite.SharedMemory] database for adapter, other paths are unchanged.
+func adapterMemoryPath(adapter DriverAdapter, path string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(path, "file:"), "?")
+	if _, ok := adapter.(MemoryAdapter); !ok || path != SharedMemory(name).String() {
+		return path
+	}
+	return memoryPath(adapter, name).String()
+}
+
+// memoryPath returns the path of the shared in-memory database name for adapter.
+func memoryPath(adapter DriverAdapter, name string) Path {
+	if m, ok := adapter.(MemoryAdapter); ok {
+		return m.MemoryPath(name)
+	}
+	return SharedMemory(name)
+}
@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_memoryPath(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		want   string
+	}{
+		{DriverMattn, "file:test?mode=memory&cache=shared"},
+		{DriverModernc, "file:test?mode=memory&cache=shared"},
+		{DriverNcruces, "file:/test?vfs=memdb"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(string(tc.driver), func(t *testing.T) {
+			t.Parallel()
+
+			adapter, ok := lookupDriverAdapter(tc.driver)
+			if !ok {
+				t.Fatal("expected a registered adapter")
+			}
+			if got := memoryPath(adapter, "test").String(); got != tc.want {
+				t.Fatalf("expected '%s', got '%s'", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_connectMemory_EmptyName(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := connectMemory(openConnector, "", WithDriver(DriverModernc))
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidPath, err)
+	}
+}
+
+func Test_connectMemory_ErrorWithOpen(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := connectMemory(openConnector, "test", WithDriver(DriverModernc), WithDriverName("unregistered"))
+	if err == nil {
+		t.Fatal("expected an error for an unregistered driver")
+	}
+}
@@ -0,0 +1,320 @@
+/*
+Package migrate applies numbered SQL migrations from a [fs.FS] to a SQLite database.
+
+The applied version is tracked per `PRAGMA user_version` and every migration step runs in its own transaction.
+
+Migrations are read from the root of the [fs.FS] and have to be named like:
+
+	0001_create_users.up.sql
+	0001_create_users.down.sql
+	0002_add_email.sql
+
+The leading number is the version, a file without ".up" or ".down" is an up migration.
+Files without the ".sql" extension are ignored.
+
+# Example
+
+	//go:embed migrations/*.sql
+	var migrations embed.FS
+
+	func main() {
+		fsys, _ := fs.Sub(migrations, "migrations")
+		m, err := migrate.New(fsys)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := m.Up(context.Background(), db); err != nil {
+			panic(err)
+		}
+	}
+*/
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrDuplicateVersion will be returned if two migrations share the same version.
+var ErrDuplicateVersion = errors.New("duplicate migration version")
+
+// ErrInvalidFilename will be returned if a ".sql" file doesn't follow the migration naming scheme.
+var ErrInvalidFilename = errors.New("invalid migration filename")
+
+// ErrMissingDown will be returned if a migration should be reverted, but has no down migration.
+var ErrMissingDown = errors.New("missing down migration")
+
+// ErrUnknownVersion will be returned if a version doesn't match any migration.
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+var regexFilename = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+// Migration is a single migration step.
+type Migration struct {
+	Version int    // Version stored in `PRAGMA user_version` after the migration was applied
+	Name    string // Name of the migration without version and extension
+	Up      string // SQL to apply the migration
+	Down    string // SQL to revert the migration, can be empty
+}
+
+// Migrator applies [migrate.Migration] steps to a database.
+type Migrator struct {
+	migrations []Migration
+	dryRun     bool
+	target     int
+}
+
+// Option is a func to set options for the [migrate.Migrator].
+type Option func(m *Migrator)
+
+// WithDryRun will just return the migrations which would be applied or reverted, without executing them.
+func WithDryRun() Option {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+// WithTargetVersion will set the version [migrate.Migrator.Up] and [migrate.Migrator.Down] should migrate to.
+//
+// Without a target version Up migrates to the latest version and Down reverts a single migration.
+// The version 0 is the empty database.
+func WithTargetVersion(version int) Option {
+	return func(m *Migrator) {
+		m.target = version
+	}
+}
+
+// New reads all migrations from fsys.
+func New(fsys fs.FS, opts ...Option) (*Migrator, error) {
+	m := &Migrator{target: -1}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m.migrations = migrations
+
+	if m.target > 0 && m.index(m.target) == -1 {
+		return nil, fmt.Errorf("target version %d: %w", m.target, ErrUnknownVersion)
+	}
+
+	return m, nil
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := regexFilename.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("given '%s', %w", entry.Name(), ErrInvalidFilename)
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("given '%s', %w", entry.Name(), ErrInvalidFilename)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("version %d: %w", version, ErrDuplicateVersion)
+		}
+
+		if match[3] == ".down" {
+			if migration.Down != "" {
+				return nil, fmt.Errorf("version %d: %w", version, ErrDuplicateVersion)
+			}
+			migration.Down = string(content)
+		} else {
+			if migration.Up != "" {
+				return nil, fmt.Errorf("version %d: %w", version, ErrDuplicateVersion)
+			}
+			migration.Up = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) index(version int) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// Migrations returns all known migrations ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	migrations := make([]Migration, len(m.migrations))
+	copy(migrations, m.migrations)
+	return migrations
+}
+
+// Latest returns the latest known version or 0 without any migration.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current version of the database.
+func Version(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version;").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Pending returns the migrations [migrate.Migrator.Up] would apply.
+func (m *Migrator) Pending(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	current, err := Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return m.pending(current), nil
+}
+
+func (m *Migrator) pending(current int) []Migration {
+	target := m.target
+	if target < 0 {
+		target = m.Latest()
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > current && migration.Version <= target {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// Up applies all pending migrations up to the target version or the latest version.
+//
+// The applied migrations are returned, or the migrations which would be applied per [migrate.WithDryRun].
+func (m *Migrator) Up(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	current, err := Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := m.pending(current)
+	if m.dryRun {
+		return pending, nil
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, migration := range pending {
+		if err := step(ctx, db, migration.Up, migration.Version); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) up: %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the latest applied migration or all migrations down to the target version.
+//
+// The reverted migrations are returned, or the migrations which would be reverted per [migrate.WithDryRun].
+func (m *Migrator) Down(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	current, err := Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	last := m.index(current)
+	if last == -1 {
+		return nil, fmt.Errorf("database version %d: %w", current, ErrUnknownVersion)
+	}
+	target := m.target
+	if target < 0 {
+		target = previousVersion(m.migrations, last)
+	}
+
+	var reverts []Migration
+	for i := last; i >= 0 && m.migrations[i].Version > target; i-- {
+		if m.migrations[i].Down == "" {
+			return nil, fmt.Errorf("migration %d (%s): %w", m.migrations[i].Version, m.migrations[i].Name, ErrMissingDown)
+		}
+		reverts = append(reverts, m.migrations[i])
+	}
+	if m.dryRun {
+		return reverts, nil
+	}
+
+	reverted := make([]Migration, 0, len(reverts))
+	for i, migration := range reverts {
+		if err := step(ctx, db, migration.Down, previousVersion(m.migrations, last-i)); err != nil {
+			return reverted, fmt.Errorf("migration %d (%s) down: %w", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration)
+	}
+	return reverted, nil
+}
+
+func previousVersion(migrations []Migration, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	return migrations[i-1].Version
+}
+
+func step(ctx context.Context, db *sql.DB, query string, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(query) != "" {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	// PRAGMA doesn't support bind parameters.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", version)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
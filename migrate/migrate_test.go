@@ -0,0 +1,302 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/lanz-dev/go-sqlite/migrate"
+)
+
+var errUnitTest = errors.New("unittest")
+
+func buildMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	return db, mock
+}
+
+func buildFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email TEXT;")},
+		"0002_add_email.down.sql":    {Data: []byte("ALTER TABLE users DROP email;")},
+		"0003_add_index.sql":         {Data: []byte("CREATE INDEX users_email ON users (email);")},
+		"README.md":                  {Data: []byte("ignored")},
+	}
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectQuery("PRAGMA user_version;").
+		WillReturnRows(sqlmock.NewRows([]string{"user_version"}).AddRow(version))
+}
+
+func expectStep(mock sqlmock.Sqlmock, query string, version string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("PRAGMA user_version = " + version).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	migrations := m.Migrations()
+	if len(migrations) != 3 {
+		t.Fatalf("expected '%d' migrations, got '%d'", 3, len(migrations))
+	}
+	if migrations[0].Name != "create_users" || migrations[0].Down != "DROP TABLE users;" {
+		t.Errorf("unexpected migration '%v'", migrations[0])
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("expected no down migration, got '%s'", migrations[2].Down)
+	}
+	if m.Latest() != 3 {
+		t.Errorf("expected '%d', got '%d'", 3, m.Latest())
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		opts    []migrate.Option
+		wantErr error
+	}{
+		{"InvalidFilename", fstest.MapFS{"create_users.sql": {}}, nil, migrate.ErrInvalidFilename},
+		{"VersionZero", fstest.MapFS{"0_create_users.sql": {}}, nil, migrate.ErrInvalidFilename},
+		{"DuplicateVersion", fstest.MapFS{"1_a.sql": {}, "1_b.sql": {}}, nil, migrate.ErrDuplicateVersion},
+		{"DuplicateUp", fstest.MapFS{"1_a.sql": {Data: []byte("a")}, "1_a.up.sql": {Data: []byte("b")}}, nil, migrate.ErrDuplicateVersion},
+		{"UnknownTarget", buildFS(), []migrate.Option{migrate.WithTargetVersion(5)}, migrate.ErrUnknownVersion},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := migrate.New(tc.fsys, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 1)
+	expectStep(mock, "ALTER TABLE users ADD email TEXT;", "2")
+	expectStep(mock, "CREATE INDEX users_email", "3")
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	applied, err := m.Up(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("expected '%d' applied migrations, got '%d'", 2, len(applied))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Up_TargetVersion(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 0)
+	expectStep(mock, "CREATE TABLE users", "1")
+	expectStep(mock, "ALTER TABLE users ADD email TEXT;", "2")
+
+	m, err := migrate.New(buildFS(), migrate.WithTargetVersion(2))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := m.Up(context.Background(), db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Up_DryRun(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 2)
+
+	m, err := migrate.New(buildFS(), migrate.WithDryRun())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	planned, err := m.Up(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(planned) != 1 || planned[0].Version != 3 {
+		t.Errorf("expected migration '%d' to be planned, got '%v'", 3, planned)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Up_WithError(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 0)
+	expectStep(mock, "CREATE TABLE users", "1")
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE").WillReturnError(errUnitTest)
+	mock.ExpectRollback()
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	applied, err := m.Up(context.Background(), db)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("expected '%d' applied migrations, got '%d'", 1, len(applied))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 2)
+	expectStep(mock, "ALTER TABLE users DROP email;", "1")
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	reverted, err := m.Down(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Errorf("expected migration '%d' to be reverted, got '%v'", 2, reverted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Down_TargetVersion(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 2)
+	expectStep(mock, "ALTER TABLE users DROP email;", "1")
+	expectStep(mock, "DROP TABLE users;", "0")
+
+	m, err := migrate.New(buildFS(), migrate.WithTargetVersion(0))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := m.Down(context.Background(), db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Down_MissingDown(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 3)
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	_, err = m.Down(context.Background(), db)
+	if !errors.Is(err, migrate.ErrMissingDown) {
+		t.Fatalf("expect error to be '%s', got '%s'", migrate.ErrMissingDown, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrator_Down_UnknownVersion(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 42)
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	_, err = m.Down(context.Background(), db)
+	if !errors.Is(err, migrate.ErrUnknownVersion) {
+		t.Fatalf("expect error to be '%s', got '%s'", migrate.ErrUnknownVersion, err)
+	}
+}
+
+func TestMigrator_Pending(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	expectVersion(mock, 0)
+
+	m, err := migrate.New(buildFS())
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	pending, err := m.Pending(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if len(pending) != 3 {
+		t.Errorf("expected '%d' pending migrations, got '%d'", 3, len(pending))
+	}
+}
@@ -1,8 +1,27 @@
 package sqlite
 
+import (
+	"fmt"
+	"io/fs"
+	"time"
+)
+
 // Option is a func to set configuration options for SQLite.
+//
+// Use [sqlite.OptionFunc] for an option which can fail.
 type Option func(c *Config)
 
+// OptionFunc returns an [sqlite.Option] for fn, which reports an invalid value per its error.
+//
+// The errors of all options are joined and returned by [sqlite.Connect] and [sqlite.ConnectPool].
+func OptionFunc(fn func(c *Config) error) Option {
+	return func(c *Config) {
+		if err := fn(c); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+}
+
 // WithAutoVacuumMode will set the auto vacuum mode.
 //
 // Setting the value [sqlite.AutoVacuumDefault] will not set the pragma at all and uses the driver default behaviour.
@@ -16,12 +35,43 @@ func WithAutoVacuumMode(mode AutoVacuumMode) Option {
 
 // WithBusyTimeout will set the busy timeout.
 //
+// The timeout is in milliseconds, see [sqlite.WithBusyTimeoutDuration].
 // Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+// A negative value is reported as [sqlite.ErrInvalidOption].
 //
 // See https://www.sqlite.org/pragma.html#pragma_busy_timeout.
 func WithBusyTimeout(timout int) Option {
-	return func(c *Config) {
+	return OptionFunc(func(c *Config) error {
 		c.BusyTimeout = timout
+		return checkBusyTimeout(timout)
+	})
+}
+
+// WithBusyTimeoutDuration will set the busy timeout, rounded up to full milliseconds.
+//
+// Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+// A negative value is reported as [sqlite.ErrInvalidOption].
+//
+// See https://www.sqlite.org/pragma.html#pragma_busy_timeout.
+func WithBusyTimeoutDuration(timeout time.Duration) Option {
+	return OptionFunc(func(c *Config) error {
+		if timeout < 0 {
+			return fmt.Errorf("given busy timeout '%s', %w", timeout, ErrInvalidOption)
+		}
+		c.BusyTimeout = int((timeout + time.Millisecond - 1) / time.Millisecond)
+		return nil
+	})
+}
+
+// WithCacheSize will set the suggested number of cached pages.
+//
+// A negative value sets the cache size in KiB instead of pages, e.g. -2000 for 2 MB.
+// Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+//
+// See https://www.sqlite.org/pragma.html#pragma_cache_size.
+func WithCacheSize(size int) Option {
+	return func(c *Config) {
+		c.CacheSize = size
 	}
 }
 
@@ -34,6 +84,51 @@ func WithCaseSensitiveLike(enabled bool) Option {
 	}
 }
 
+// WithCellSizeCheck will enable or disable the additional sanity checks of database pages.
+//
+// See https://www.sqlite.org/pragma.html#pragma_cell_size_check.
+func WithCellSizeCheck(enabled bool) Option {
+	return func(c *Config) {
+		c.CellSizeCheck = enabled
+	}
+}
+
+// WithConfig will replace the whole configuration with config, e.g. decoded from a config file into [sqlite.DefaultConfig].
+//
+// Later options still change the configuration. config is validated on [sqlite.Connect] like every other option.
+func WithConfig(config Config) Option {
+	return func(c *Config) {
+		config.errs = c.errs
+		config.activity = nil
+		*c = config
+	}
+}
+
+// WithConnInit will add fn to the funcs run on every new connection of the pool, e.g. to attach databases or
+// to create temporary tables.
+//
+// The funcs run in the order they were added, after the pragmas which can't be set per DSN.
+// A connection is discarded, if fn returns an error.
+func WithConnInit(fn ConnInitFunc) Option {
+	return func(c *Config) {
+		c.ConnInit = append(c.ConnInit, fn)
+	}
+}
+
+// WithConnectorWrapper will add wrap, which wraps the [driver.Connector] of the [sql.DB], e.g. for instrumentation.
+//
+// wrap gets the final config, after the driver was selected.
+// The connections of the wrapped connector are already initialized and implement Unwrap() [driver.Conn],
+// a wrapping connection should implement it as well, so [sqlite.UnwrapConn] can reach the connection of the driver.
+// The wrappers are applied in the order they were added, so the last one is the outermost.
+// A wrapper implementing [io.Closer] is closed by [sql.DB.Close] and has to close the wrapped connector.
+// The Driver method of a wrapper should return the driver of the wrapped connector, which [sqlite.Stats] relies on.
+func WithConnectorWrapper(wrap ConnectorWrapper) Option {
+	return func(c *Config) {
+		c.ConnectorWrappers = append(c.ConnectorWrappers, wrap)
+	}
+}
+
 // WithDeferredForeignKeys will enable or disable deferred foreign keys.
 //
 // See https://www.sqlite.org/pragma.html#pragma_defer_foreign_keys.
@@ -61,12 +156,28 @@ func WithDisabledLimits() Option {
 // Available options are:
 //   - [sqlite.DriverMattn] for "github.com/mattn/go-sqlite3"
 //   - [sqlite.DriverModernc] for "modernc.org/sqlite"
+//   - [sqlite.DriverNcruces] for "github.com/ncruces/go-sqlite3"
+//
+// Other drivers can be used after registering a [sqlite.DriverAdapter] per [sqlite.RegisterDriverAdapter].
 func WithDriver(driver Driver) Option {
 	return func(c *Config) {
 		c.Driver = driver
 	}
 }
 
+// WithDriverPreference will select the first registered driver of drivers, instead of the first one by name.
+//
+// Without a preference [sqlite.Connect] selects the first of [sqlite.DetectedDrivers], so a binary
+// importing "modernc.org/sqlite" ("sqlite") and "github.com/mattn/go-sqlite3" ("sqlite3") uses modernc.
+// [sqlite.ErrDriverNotRegistered] is returned, if none of drivers is registered.
+//
+// The environment variable [sqlite.EnvDriver] overrides the preference. [sqlite.WithDriver] skips the detection.
+func WithDriverPreference(drivers ...Driver) Option {
+	return func(c *Config) {
+		c.DriverPreference = drivers
+	}
+}
+
 // WithDriverName will set the name used for [sql.Open].
 //
 // Normally you just need this, if you modified the sql [driver.Driver]
@@ -77,6 +188,18 @@ func WithDriverName(name string) Option {
 	}
 }
 
+// WithDSN will set the path and all pragmas found in dsn.
+//
+// The DSN can be written for "github.com/mattn/go-sqlite3" or "modernc.org/sqlite",
+// see [sqlite.ParseDSN] for the understood parameters.
+// Pragmas missing in dsn keep their current value and can be overwritten by later options.
+// An invalid dsn is reported by [sqlite.Connect].
+func WithDSN(dsn string) Option {
+	return OptionFunc(func(c *Config) error {
+		return parseDSN(c, dsn, "")
+	})
+}
+
 // WithForeignKeySupport will enable or disable the foreign key support.
 //
 // See https://www.sqlite.org/pragma.html#pragma_foreign_keys.
@@ -100,24 +223,177 @@ func WithJournalMode(mode JournalMode) Option {
 // WithJournalSizeLimit will set the journal size limit.
 //
 // Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+// A value below -1, which means no limit, is reported as [sqlite.ErrInvalidOption].
 //
 // See https://www.sqlite.org/pragma.html#pragma_journal_size_limit.
 func WithJournalSizeLimit(limit int) Option {
-	return func(c *Config) {
+	return OptionFunc(func(c *Config) error {
 		c.JournalSizeLimit = limit
+		return checkJournalSizeLimit(limit)
+	})
+}
+
+// WithLockingMode will set the locking mode for the connection.
+//
+// Setting the value [sqlite.LockingDefault] will not set the pragma at all and uses the driver default behaviour.
+//
+// See https://www.sqlite.org/pragma.html#pragma_locking_mode.
+func WithLockingMode(mode LockingMode) Option {
+	return func(c *Config) {
+		c.LockingMode = mode
+	}
+}
+
+// WithMaintenance will start a [sqlite.Maintainer] on [sqlite.Connect].
+//
+// The incremental vacuum task is only enabled with [sqlite.AutoVacuumIncremental].
+// The [sqlite.Maintainer] is stopped by [sqlite.ShutdownContext].
+func WithMaintenance(cfg MaintenanceConfig) Option {
+	return func(c *Config) {
+		c.Maintenance = &cfg
 	}
 }
 
+// WithMigrations will apply all pending migrations from fsys on [sqlite.Connect].
+//
+// Connect fails if the migrations can't be applied, so the returned database is never behind the latest migration.
+// See package "github.com/lanz-dev/go-sqlite/migrate" for the naming scheme of the migration files.
+func WithMigrations(fsys fs.FS) Option {
+	return func(c *Config) {
+		c.Migrations = fsys
+	}
+}
+
+// WithMmapSize will set the max number of bytes used for memory-mapped I/O.
+//
+// Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+// A negative value is reported as [sqlite.ErrInvalidOption].
+//
+// See https://www.sqlite.org/pragma.html#pragma_mmap_size.
+func WithMmapSize(size int64) Option {
+	return OptionFunc(func(c *Config) error {
+		c.MmapSize = size
+		return checkMmapSize(size)
+	})
+}
+
+// WithPageSize will set the page size of a new database.
+//
+// The page size of an existing database, or a database in WAL mode, can only be changed by a VACUUM.
+// Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+// A size, which isn't a power of two between 512 and 65536, is reported as [sqlite.ErrInvalidOption].
+//
+// See https://www.sqlite.org/pragma.html#pragma_page_size.
+func WithPageSize(size int) Option {
+	return OptionFunc(func(c *Config) error {
+		c.PageSize = size
+		return checkPageSize(size)
+	})
+}
+
 // WithPath will set the db path for sqlite
 //
-// dbPath should be in format "file:your/path/to/data.db" or ":memory" for an in-memory sqlite connection.
-// The format will be checked per regex `^file\:.+\..+$` on [sqlite.Connect].
+// dbPath can be a `file:` URI like "file:your/path/to/data.db?mode=ro", a plain path like "/data/db"
+// or [sqlite.MemoryPath] for an in-memory sqlite connection. Use [sqlite.WithURI] to build the URI.
 func WithPath(dbPath string) Option {
 	return func(c *Config) {
 		c.Path = dbPath
 	}
 }
 
+// WithPreset will replace all pragmas and the connection limit with the ones of preset, see [sqlite.Preset] for
+// the pragmas each preset sets.
+//
+// Later options override single settings of the preset:
+//
+//	db, err := sqlite.Connect(
+//		sqlite.WithPath("file:data.db"),
+//		sqlite.WithPreset(sqlite.PresetBulkImport),
+//		sqlite.WithCacheSize(-65536),
+//	)
+func WithPreset(preset Preset) Option {
+	return OptionFunc(func(c *Config) error {
+		return applyPreset(c, preset)
+	})
+}
+
+// WithQueryHook will add hook, which is called for every query of the [sql.DB], e.g. for logging or tracing.
+//
+// Before of all hooks is called in the order they were added, After in reverse order.
+// A hook implementing [sqlite.TxHook] is called for the transactions as well.
+// [sqlite.SlogHook] and [sqlite.SlowQueryHook] log the queries per [log/slog].
+func WithQueryHook(hook Hook) Option {
+	return func(c *Config) {
+		c.QueryHooks = append(c.QueryHooks, hook)
+	}
+}
+
+// WithQueryOnly will prevent all changes to the database file.
+//
+// See https://www.sqlite.org/pragma.html#pragma_query_only.
+func WithQueryOnly(enabled bool) Option {
+	return func(c *Config) {
+		c.QueryOnly = enabled
+	}
+}
+
+// WithReadConnections will set the max open connections of the reader pool used by [sqlite.ConnectPool].
+//
+// Setting a value of 0 will use [runtime.NumCPU]. A negative value is reported as [sqlite.ErrInvalidOption].
+func WithReadConnections(n int) Option {
+	return OptionFunc(func(c *Config) error {
+		c.ReadConnections = n
+		return checkReadConnections(n)
+	})
+}
+
+// WithRecursiveTriggers will enable or disable recursive triggers.
+//
+// See https://www.sqlite.org/pragma.html#pragma_recursive_triggers.
+func WithRecursiveTriggers(enabled bool) Option {
+	return func(c *Config) {
+		c.RecursiveTriggers = enabled
+	}
+}
+
+// WithRequire will fail [sqlite.Connect] with [sqlite.ErrMissingCapability], if the linked SQLite library misses
+// one of the requirements, see [sqlite.Capabilities].
+//
+// A requirement is a minimal version like "3.35.0" or a feature of [sqlite.Caps.Has] like "FTS5" or "JSON1":
+//
+//	db, err := sqlite.Connect(sqlite.WithRequire("3.35.0", "FTS5"))
+func WithRequire(requirements ...string) Option {
+	return OptionFunc(func(c *Config) error {
+		if err := checkRequirements(requirements); err != nil {
+			return err
+		}
+		c.Require = append(c.Require, requirements...)
+		return nil
+	})
+}
+
+// WithSecureDelete will set the secure delete mode for the connection.
+//
+// Setting the value [sqlite.SecureDeleteDefault] will not set the pragma at all and uses the driver default behaviour.
+//
+// See https://www.sqlite.org/pragma.html#pragma_secure_delete.
+func WithSecureDelete(mode SecureDelete) Option {
+	return func(c *Config) {
+		c.SecureDelete = mode
+	}
+}
+
+// WithStrictPragmas will verify on [sqlite.Connect] that the driver applied all configured pragmas.
+//
+// A *[sqlite.PragmaMismatchError] is returned for pragmas with an unexpected value,
+// e.g. the journal mode of an in-memory database is always "MEMORY".
+// The pragma `case_sensitive_like` can't be read and isn't verified.
+func WithStrictPragmas() Option {
+	return func(c *Config) {
+		c.StrictPragmas = true
+	}
+}
+
 // WithSyncMode will set the sync mode for the connection.
 //
 // Setting the value [sqlite.SyncDefault] will not set the pragma at all and uses the driver default behaviour.
@@ -128,3 +404,48 @@ func WithSyncMode(sync SyncMode) Option {
 		c.SyncMode = sync
 	}
 }
+
+// WithTempStore will set where temporary tables and indices are stored.
+//
+// Setting the value [sqlite.TempStoreDefault] will not set the pragma at all and uses the driver default behaviour.
+//
+// See https://www.sqlite.org/pragma.html#pragma_temp_store.
+func WithTempStore(store TempStore) Option {
+	return func(c *Config) {
+		c.TempStore = store
+	}
+}
+
+// WithTxLock will set the mode used to begin all transactions of the connection.
+//
+// Setting the value [sqlite.TxDefault] will not set the mode at all and uses the driver default behaviour.
+// Use [sqlite.WithTx] to set the mode of a single transaction.
+//
+// See https://www.sqlite.org/lang_transaction.html.
+func WithTxLock(mode TxMode) Option {
+	return func(c *Config) {
+		c.TxLock = mode
+	}
+}
+
+// WithURI will set the db path for sqlite to the URI built per [sqlite.Path].
+//
+// The URI parameters are merged with the parameters of the driver.
+func WithURI(path Path) Option {
+	return func(c *Config) {
+		c.Path = path.String()
+		c.CreateDirs = path.createDirs
+	}
+}
+
+// WithWALAutoCheckpoint will set the number of WAL pages after which a checkpoint is run automatically.
+//
+// A negative value disables the automatic checkpoints.
+// Setting a value of 0 will not set the pragma at all and uses the driver default behaviour.
+//
+// See https://www.sqlite.org/pragma.html#pragma_wal_autocheckpoint.
+func WithWALAutoCheckpoint(pages int) Option {
+	return func(c *Config) {
+		c.WALAutoCheckpoint = pages
+	}
+}
@@ -1,7 +1,14 @@
 package sqlite
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func optionRunner(config *Config, opts ...Option) {
@@ -10,6 +17,32 @@ func optionRunner(config *Config, opts ...Option) {
 	}
 }
 
+func TestOptionFunc(t *testing.T) {
+	t.Parallel()
+
+	config, err := buildConfig(
+		WithDriver(DriverMattn),
+		OptionFunc(func(c *Config) error {
+			c.CacheSize = -4000
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.CacheSize != -4000 {
+		t.Errorf("expected '%d', got '%d'", -4000, config.CacheSize)
+	}
+
+	_, err = buildConfig(
+		WithDriver(DriverMattn),
+		OptionFunc(func(*Config) error { return errUnitTest }),
+	)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+}
+
 func TestWithAutoVacuumMode(t *testing.T) {
 	t.Parallel()
 
@@ -44,6 +77,55 @@ func TestWithBusyTimeout(t *testing.T) {
 	}
 }
 
+func TestWithBusyTimeout_Negative(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(WithDriver(DriverMattn), WithBusyTimeout(-5))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidOption, err)
+	}
+}
+
+func TestWithBusyTimeoutDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[time.Duration]int{
+		0:                       0,
+		5 * time.Second:         5000,
+		1500 * time.Microsecond: 2,
+	}
+	for timeout, expected := range tests {
+		config := newConfig()
+		optionRunner(config, WithBusyTimeoutDuration(timeout))
+
+		if got := config.BusyTimeout; got != expected {
+			t.Errorf("expected '%d', got '%d'", expected, got)
+		}
+	}
+
+	_, err := buildConfig(WithDriver(DriverMattn), WithBusyTimeoutDuration(-time.Second))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidOption, err)
+	}
+}
+
+func TestWithCacheSize(t *testing.T) {
+	t.Parallel()
+
+	expected := -2000
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithCacheSize(expected),
+	)
+
+	got := config.CacheSize
+	if got != expected {
+		t.Errorf("expected '%d', got '%d'", expected, got)
+	}
+}
+
 func TestWithCaseSensitiveLike(t *testing.T) {
 	t.Parallel()
 
@@ -59,6 +141,78 @@ func TestWithCaseSensitiveLike(t *testing.T) {
 	}
 }
 
+func TestWithCellSizeCheck(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithCellSizeCheck(true),
+	)
+
+	got := config.CellSizeCheck
+	if !got {
+		t.Errorf("expected '%v', got '%v'", true, got)
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	t.Parallel()
+
+	expected := DefaultConfig()
+	expected.Path = "file:data.db"
+	expected.ForeignKey = false
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithConfig(expected),
+		WithCacheSize(-2000),
+	)
+
+	if config.Path != expected.Path || config.ForeignKey || config.CacheSize != -2000 {
+		t.Errorf("expected '%+v', got '%+v'", expected, *config)
+	}
+}
+
+func TestWithConnInit(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithConnInit(func(context.Context, *sql.Conn) error { return nil }),
+		WithConnInit(func(context.Context, *sql.Conn) error { return errUnitTest }),
+	)
+
+	got := len(config.ConnInit)
+	if got != 2 {
+		t.Fatalf("expected '%d', got '%d'", 2, got)
+	}
+	if err := config.ConnInit[1](context.Background(), nil); !errors.Is(err, errUnitTest) {
+		t.Errorf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+}
+
+func TestWithConnectorWrapper(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithConnectorWrapper(func(_ *Config, c driver.Connector) driver.Connector { return c }),
+		WithConnectorWrapper(func(*Config, driver.Connector) driver.Connector { return nil }),
+	)
+
+	got := len(config.ConnectorWrappers)
+	if got != 2 {
+		t.Fatalf("expected '%d', got '%d'", 2, got)
+	}
+	if c := config.ConnectorWrappers[1](config, dsnConnector{}); c != nil {
+		t.Errorf("expected '%v', got '%v'", nil, c)
+	}
+}
+
 func TestWithDeferredForeignKeys(t *testing.T) {
 	t.Parallel()
 
@@ -123,6 +277,23 @@ func TestWithDriverName(t *testing.T) {
 	}
 }
 
+func TestWithDriverPreference(t *testing.T) {
+	t.Parallel()
+
+	expected := []Driver{DriverMattn, DriverModernc}
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithDriverPreference(expected...),
+	)
+
+	got := config.DriverPreference
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+}
+
 func TestWithForeignKeySupport(t *testing.T) {
 	t.Parallel()
 
@@ -172,6 +343,89 @@ func TestWithJournalSizeLimit(t *testing.T) {
 	}
 }
 
+func TestWithLockingMode(t *testing.T) {
+	t.Parallel()
+
+	expected := LockingExclusive
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithLockingMode(expected),
+	)
+
+	got := config.LockingMode
+	if got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestWithMaintenance(t *testing.T) {
+	t.Parallel()
+
+	expected := time.Hour
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithMaintenance(MaintenanceConfig{OptimizeInterval: expected}),
+	)
+
+	if config.Maintenance == nil || config.Maintenance.OptimizeInterval != expected {
+		t.Errorf("expected optimize interval '%s', got '%v'", expected, config.Maintenance)
+	}
+}
+
+func TestWithMigrations(t *testing.T) {
+	t.Parallel()
+
+	expected := fstest.MapFS{}
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithMigrations(expected),
+	)
+
+	if config.Migrations == nil {
+		t.Errorf("expected migrations to be set")
+	}
+}
+
+func TestWithMmapSize(t *testing.T) {
+	t.Parallel()
+
+	expected := int64(268435456)
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithMmapSize(expected),
+	)
+
+	got := config.MmapSize
+	if got != expected {
+		t.Errorf("expected '%d', got '%d'", expected, got)
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	t.Parallel()
+
+	expected := 8192
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithPageSize(expected),
+	)
+
+	got := config.PageSize
+	if got != expected {
+		t.Errorf("expected '%d', got '%d'", expected, got)
+	}
+}
+
 func TestWithPath(t *testing.T) {
 	t.Parallel()
 
@@ -189,6 +443,151 @@ func TestWithPath(t *testing.T) {
 	}
 }
 
+func TestWithPreset(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithQueryOnly(true),
+		WithPreset(PresetBulkImport),
+		WithCacheSize(-1000),
+	)
+
+	if config.JournalMode != JournalOff {
+		t.Errorf("expected '%s', got '%s'", JournalOff, config.JournalMode)
+	}
+	if config.QueryOnly {
+		t.Errorf("expected '%v', got '%v'", false, config.QueryOnly)
+	}
+	if config.CacheSize != -1000 {
+		t.Errorf("expected '%d', got '%d'", -1000, config.CacheSize)
+	}
+	if len(config.errs) != 0 {
+		t.Errorf("did not expect error '%s'", errors.Join(config.errs...))
+	}
+}
+
+func TestWithQueryHook(t *testing.T) {
+	t.Parallel()
+
+	first := SlogHook{}
+	second := SlowQueryHook{Threshold: time.Second}
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithQueryHook(first),
+		WithQueryHook(second),
+	)
+
+	got := config.QueryHooks
+	if len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("expected '%v', got '%v'", []Hook{first, second}, got)
+	}
+}
+
+func TestWithQueryOnly(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithQueryOnly(true),
+	)
+
+	got := config.QueryOnly
+	if !got {
+		t.Errorf("expected '%v', got '%v'", true, got)
+	}
+}
+
+func TestWithReadConnections(t *testing.T) {
+	t.Parallel()
+
+	expected := 8
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithReadConnections(expected),
+	)
+
+	got := config.ReadConnections
+	if got != expected {
+		t.Errorf("expected '%d', got '%d'", expected, got)
+	}
+}
+
+func TestWithRecursiveTriggers(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithRecursiveTriggers(true),
+	)
+
+	got := config.RecursiveTriggers
+	if !got {
+		t.Errorf("expected '%v', got '%v'", true, got)
+	}
+}
+
+func TestWithRequire(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{"3.35.0", "FTS5", "JSON1"}
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithRequire("3.35.0", "FTS5"),
+		WithRequire("JSON1"),
+		WithRequire(""),
+	)
+
+	got := config.Require
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+	if err := errors.Join(config.errs...); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expect error to be '%s', got '%s'", ErrInvalidOption, err)
+	}
+}
+
+func TestWithSecureDelete(t *testing.T) {
+	t.Parallel()
+
+	expected := SecureDeleteFast
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithSecureDelete(expected),
+	)
+
+	got := config.SecureDelete
+	if got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestWithStrictPragmas(t *testing.T) {
+	t.Parallel()
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithStrictPragmas(),
+	)
+
+	got := config.StrictPragmas
+	if !got {
+		t.Errorf("expected '%v', got '%v'", true, got)
+	}
+}
+
 func TestWithSyncMode(t *testing.T) {
 	t.Parallel()
 
@@ -205,3 +604,74 @@ func TestWithSyncMode(t *testing.T) {
 		t.Errorf("expected '%s', got '%s'", expected, got)
 	}
 }
+
+func TestWithTempStore(t *testing.T) {
+	t.Parallel()
+
+	expected := TempStoreMemory
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithTempStore(expected),
+	)
+
+	got := config.TempStore
+	if got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestWithTxLock(t *testing.T) {
+	t.Parallel()
+
+	expected := TxImmediate
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithTxLock(expected),
+	)
+
+	got := config.TxLock
+	if got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestWithURI(t *testing.T) {
+	t.Parallel()
+
+	expected := "file:data.db?mode=ro"
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithURI(FilePath("data.db").ReadOnly().CreateDirs()),
+	)
+
+	got := config.Path
+	if got != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got)
+	}
+	if !config.CreateDirs {
+		t.Errorf("expected '%v', got '%v'", true, config.CreateDirs)
+	}
+}
+
+func TestWithWALAutoCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	expected := 500
+
+	config := newConfig()
+	optionRunner(
+		config,
+		WithWALAutoCheckpoint(expected),
+	)
+
+	got := config.WALAutoCheckpoint
+	if got != expected {
+		t.Errorf("expected '%d', got '%d'", expected, got)
+	}
+}
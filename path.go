@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MemoryPath is the path of a private in-memory database, every connection gets its own database.
+//
+// Use [sqlite.SharedMemory] to share an in-memory database between connections.
+const MemoryPath = ":memory:"
+
+// OpenMode is the mode parameter of a SQLite URI.
+//
+// See https://www.sqlite.org/uri.html#urimode.
+type OpenMode string
+
+// The available modes to open a database.
+const (
+	OpenReadOnly        OpenMode = "ro"
+	OpenReadWrite       OpenMode = "rw"
+	OpenReadWriteCreate OpenMode = "rwc"
+	OpenMemory          OpenMode = "memory"
+)
+
+// Path builds a `file:` URI for a SQLite database, use it per [sqlite.WithURI].
+//
+// All methods return a modified copy of the Path.
+//
+// See https://www.sqlite.org/uri.html.
+type Path struct {
+	name       string
+	params     []string
+	createDirs bool
+}
+
+// FilePath returns the [sqlite.Path] for the database file p.
+func FilePath(p string) Path {
+	return Path{name: p}
+}
+
+// Memory returns the [sqlite.Path] of a private in-memory database, see [sqlite.MemoryPath].
+func Memory() Path {
+	return Path{name: MemoryPath}
+}
+
+// SharedMemory returns the [sqlite.Path] of the in-memory database name,
+// which is shared by all connections of the process using the same name.
+//
+// A driver with a [sqlite.MemoryAdapter] opens it per its MemoryPath, e.g. [sqlite.DriverNcruces] with the memdb VFS.
+func SharedMemory(name string) Path {
+	return Path{name: name}.Mode(OpenMemory).setParam("cache", "shared")
+}
+
+// ReadOnly opens the database read-only, see [sqlite.OpenReadOnly].
+func (p Path) ReadOnly() Path {
+	return p.Mode(OpenReadOnly)
+}
+
+// Immutable marks the database file as unchangeable, so SQLite skips all locking and change detection.
+//
+// See https://www.sqlite.org/uri.html#uriimmutable.
+func (p Path) Immutable() Path {
+	return p.setParam("immutable", "1")
+}
+
+// Mode sets the [sqlite.OpenMode] of the database.
+func (p Path) Mode(mode OpenMode) Path {
+	return p.setParam("mode", string(mode))
+}
+
+// VFS sets the name of the VFS used to open the database.
+//
+// See https://www.sqlite.org/uri.html#urivfs.
+func (p Path) VFS(name string) Path {
+	return p.setParam("vfs", name)
+}
+
+// CreateDirs will create the parent directories of the database file on connect.
+func (p Path) CreateDirs() Path {
+	p.createDirs = true
+	return p
+}
+
+// String returns the `file:` URI of the path, or [sqlite.MemoryPath] for a private in-memory database.
+func (p Path) String() string {
+	if p.name == MemoryPath && len(p.params) == 0 {
+		return MemoryPath
+	}
+
+	uri := "file:" + escapeURIPath(p.name)
+	if len(p.params) > 0 {
+		uri += "?" + strings.Join(p.params, "&")
+	}
+	return uri
+}
+
+func (p Path) setParam(key, value string) Path {
+	param := key + "=" + url.QueryEscape(value)
+
+	params := make([]string, 0, len(p.params)+1)
+	for _, existing := range p.params {
+		if !strings.HasPrefix(existing, key+"=") {
+			params = append(params, existing)
+		}
+	}
+	p.params = append(params, param)
+	return p
+}
+
+// escapeURIPath escapes the characters of name, which have a meaning within a SQLite URI.
+func escapeURIPath(name string) string {
+	name = filepath.ToSlash(name)
+	if filepath.VolumeName(name) != "" {
+		// Windows paths like "C:/data.db" need a leading slash.
+		name = "/" + name
+	}
+	return strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(name)
+}
+
+// isMemoryPath reports whether path is an in-memory database.
+func isMemoryPath(path string) bool {
+	if path == MemoryPath || path == ":memory" {
+		return true
+	}
+	name, query, _ := strings.Cut(path, "?")
+	if name == "file::memory:" {
+		return true
+	}
+	values, err := url.ParseQuery(query)
+	return err == nil && strings.HasPrefix(name, "file:") &&
+		(values.Get("mode") == string(OpenMemory) || values.Get("vfs") == "memdb")
+}
+
+// uriFilePath returns the file system path of path, which can be a `file:` URI or a plain path.
+func uriFilePath(path string) (string, error) {
+	name, _, _ := strings.Cut(path, "?")
+	if !strings.HasPrefix(name, "file:") {
+		return name, nil
+	}
+
+	name = strings.TrimPrefix(name, "file:")
+	name, _, _ = strings.Cut(name, "#")
+	if strings.HasPrefix(name, "//") {
+		authority, rest, _ := strings.Cut(name[2:], "/")
+		if authority != "" && authority != "localhost" {
+			return "", fmt.Errorf("given '%s', %w", path, ErrInvalidPath)
+		}
+		name = "/" + rest
+	}
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return "", fmt.Errorf("given '%s', %w", path, ErrInvalidPath)
+	}
+	if len(name) > 2 && name[0] == '/' && filepath.VolumeName(name[1:]) != "" {
+		name = name[1:]
+	}
+	return filepath.FromSlash(name), nil
+}
+
+// DatabaseFile returns the file system path of the database path, which can be a `file:` URI or a plain path.
+//
+// It returns "" for an in-memory database.
+func DatabaseFile(path string) (string, error) {
+	if isMemoryPath(path) {
+		return "", nil
+	}
+	return uriFilePath(path)
+}
+
+// fileURI returns path as `file:` URI, for the drivers which only read the parameters of a URI.
+//
+// A plain path is converted per [sqlite.FilePath], [sqlite.MemoryPath] becomes "file::memory:".
+func fileURI(path string) string {
+	name, query, _ := strings.Cut(path, "?")
+	if strings.HasPrefix(name, "file:") {
+		return path
+	}
+	if name == MemoryPath {
+		name = "file::memory:"
+	} else {
+		name = FilePath(name).String()
+	}
+	if query == "" {
+		return name
+	}
+	return name + "?" + query
+}
+
+// readOnlyURI returns path as `file:` URI with the mode "ro", SQLite ignores the parameters of plain paths.
+func readOnlyURI(path string) string {
+	name, query, _ := strings.Cut(path, "?")
+	if !strings.HasPrefix(name, "file:") {
+		name = FilePath(name).String()
+	}
+	values, _ := url.ParseQuery(query)
+	values.Set("mode", string(OpenReadOnly))
+	return name + "?" + encodeQuery(values)
+}
+
+// createDirs creates the parent directories of the database file of path.
+func createDirs(path string) error {
+	if isMemoryPath(path) {
+		return nil
+	}
+	name, err := uriFilePath(path)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(filepath.Dir(name), 0o750)
+}
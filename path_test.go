@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath_String(t *testing.T) {
+	tests := []struct {
+		name string
+		path Path
+		want string
+	}{
+		{"File", FilePath("data.db"), "file:data.db"},
+		{"Absolute", FilePath("/data/db"), "file:/data/db"},
+		{"Escaped", FilePath("/data/what?#100%.db"), "file:/data/what%3f%23100%25.db"},
+		{"Memory", Memory(), ":memory:"},
+		{"MemoryWithVFS", Memory().VFS("memdb"), "file::memory:?vfs=memdb"},
+		{"SharedMemory", SharedMemory("shared"), "file:shared?mode=memory&cache=shared"},
+		{"ReadOnly", FilePath("data.db").ReadOnly(), "file:data.db?mode=ro"},
+		{"Immutable", FilePath("data.db").ReadOnly().Immutable(), "file:data.db?mode=ro&immutable=1"},
+		{"ModeReplaced", FilePath("data.db").ReadOnly().Mode(OpenReadWriteCreate), "file:data.db?mode=rwc"},
+		{"VFS", FilePath("data.db").VFS("unix dotfile"), "file:data.db?vfs=unix+dotfile"},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.path.String(); got != tc.want {
+				t.Fatalf("expected '%s', got '%s'", tc.want, got)
+			}
+			if err := validatePath(tc.path.String()); err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+		})
+	}
+}
+
+func TestPath_Copy(t *testing.T) {
+	t.Parallel()
+
+	base := FilePath("data.db").VFS("unix")
+	readOnly := base.ReadOnly()
+	readWrite := base.Mode(OpenReadWrite)
+
+	if got := readOnly.String(); got != "file:data.db?vfs=unix&mode=ro" {
+		t.Errorf("expected '%s', got '%s'", "file:data.db?vfs=unix&mode=ro", got)
+	}
+	if got := readWrite.String(); got != "file:data.db?vfs=unix&mode=rw" {
+		t.Errorf("expected '%s', got '%s'", "file:data.db?vfs=unix&mode=rw", got)
+	}
+}
+
+func Test_uriFilePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error
+	}{
+		{"data.db", "data.db", nil},
+		{"data.db?mode=ro", "data.db", nil},
+		{"file:data.db?mode=ro", "data.db", nil},
+		{"file:/data/what%3f%23100%25.db", "/data/what?#100%.db", nil},
+		{"file:///data/db", "/data/db", nil},
+		{"file://localhost/data/db#fragment", "/data/db", nil},
+		{"file://host/data/db", "", ErrInvalidPath},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run("With path '"+tc.path+"'", func(t *testing.T) {
+			t.Parallel()
+
+			got, err := uriFilePath(tc.path)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if got != filepath.FromSlash(tc.want) {
+				t.Fatalf("expected '%s', got '%s'", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_isMemoryPath(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{":memory:", ":memory", "file::memory:?cache=shared", "file:shared?mode=memory", "file:/shared?vfs=memdb"} {
+		if !isMemoryPath(path) {
+			t.Errorf("expected '%s' to be in-memory", path)
+		}
+	}
+	for _, path := range []string{"file:data.db", "data.db?mode=memory", "file:memory.db"} {
+		if isMemoryPath(path) {
+			t.Errorf("expected '%s' not to be in-memory", path)
+		}
+	}
+}
+
+func TestDatabaseFile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		":memory:":                     "",
+		"file:shared?mode=memory":      "",
+		"/data/db":                     filepath.FromSlash("/data/db"),
+		"file:/data/db?_pragma=x":      filepath.FromSlash("/data/db"),
+		"file://localhost/data/a%3fdb": filepath.FromSlash("/data/a?db"),
+	}
+	for path, expected := range tests {
+		got, err := DatabaseFile(path)
+		if err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+
+	if _, err := DatabaseFile("file://host/data.db"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expect error to be '%s', got '%s'", ErrInvalidPath, err)
+	}
+}
+
+func Test_fileURI(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		MemoryPath:                "file::memory:",
+		"file:data.db?mode=ro":    "file:data.db?mode=ro",
+		"data.db":                 "file:data.db",
+		"/data/db?cache=private":  "file:/data/db?cache=private",
+		"dir/data #1.db":          "file:dir/data %231.db",
+		"file:shared?mode=memory": "file:shared?mode=memory",
+		":memory:?_pragma=x":      "file::memory:?_pragma=x",
+	}
+	for path, expected := range tests {
+		if got := fileURI(path); got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+}
+
+func Test_readOnlyURI(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"file:data.db":         "file:data.db?mode=ro",
+		"file:data.db?vfs=x":   "file:data.db?mode=ro&vfs=x",
+		"/data/db":             "file:/data/db?mode=ro",
+		"/data/db?cache=priv":  "file:/data/db?cache=priv&mode=ro",
+		"file:data.db?mode=rw": "file:data.db?mode=ro",
+	}
+	for path, expected := range tests {
+		if got := readOnlyURI(path); got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+}
+
+func Test_createDirs(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	if err := createDirs(FilePath(filepath.Join(dir, "data.db")).String()); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("expected directory '%s', got '%v'", dir, err)
+	}
+
+	if err := createDirs(MemoryPath); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+}
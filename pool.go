@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ErrInMemoryPool will be returned by [sqlite.ConnectPool] for an in-memory database,
+// because every connection of an in-memory database has its own private database.
+var ErrInMemoryPool = errors.New("in-memory database cannot be split into reader and writer pools")
+
+// DB is a SQLite database handle with a separate reader and writer pool.
+//
+// Writes are sent to a writer pool limited to a single connection and reads are
+// sent to a read-only pool with multiple connections, so reads don't queue behind writes in WAL mode.
+//
+// All operations and transactions of both pools are tracked, so [sqlite.DB.ShutdownContext] can wait for them.
+type DB struct {
+	config *Config
+	writer *sql.DB
+	reader *sql.DB
+}
+
+// ConnectPool will connect to a SQLite database with a writer and a reader pool.
+//
+// The writer pool is always limited to a single connection.
+// The reader pool is opened read-only with `mode=ro` and `PRAGMA query_only`
+// and can be sized per [sqlite.WithReadConnections].
+//
+// The same options and defaults as for [sqlite.Connect] are used, but the path can't be an in-memory database.
+func ConnectPool(opts ...Option) (*DB, error) {
+	return connectPool(openFunc, opts...)
+}
+
+func buildReaderConfig(config *Config) *Config {
+	reader := *config
+	reader.LimitConnection = false
+
+	// The writer is responsible for these pragmas, a read-only connection can't change them anyway.
+	reader.AutoVacuumMode = AutoVacuumDefault
+	reader.JournalMode = JournalDefault
+	reader.JournalSizeLimit = 0
+	reader.PageSize = 0
+	reader.Migrations = nil
+
+	reader.QueryOnly = true
+	reader.Path = readOnlyURI(reader.Path)
+	reader.DSN = buildDriverDSN(&reader)
+
+	return &reader
+}
+
+func connectPool(openFunc sqlOpenFunc, opts ...Option) (*DB, error) {
+	config, err := buildConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if isMemoryPath(config.Path) {
+		return nil, ErrInMemoryPool
+	}
+	config.LimitConnection = true
+	config.activity = newActivity()
+
+	writer, err := openDB(openFunc, config)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := openDB(openFunc, buildReaderConfig(config))
+	if err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+
+	readConnections := config.ReadConnections
+	if readConnections <= 0 {
+		readConnections = runtime.NumCPU()
+	}
+	reader.SetMaxOpenConns(readConnections)
+	reader.SetMaxIdleConns(readConnections)
+
+	if config.Maintenance != nil {
+		StartMaintenance(context.Background(), writer, buildMaintenanceConfig(config))
+	}
+
+	return &DB{
+		config: config,
+		writer: writer,
+		reader: reader,
+	}, nil
+}
+
+// Config returns the [sqlite.Config] used for the writer pool.
+func (db *DB) Config() Config {
+	return *db.config
+}
+
+// Reader returns the read-only pool.
+func (db *DB) Reader() *sql.DB {
+	return db.reader
+}
+
+// Writer returns the writer pool.
+func (db *DB) Writer() *sql.DB {
+	return db.writer
+}
+
+// Begin starts a transaction on the writer pool.
+func (db *DB) Begin() (*sql.Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction on the writer pool.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return db.writer.BeginTx(ctx, opts)
+}
+
+// Exec executes a query on the writer pool.
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a query on the writer pool.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.writer.ExecContext(ctx, query, args...)
+}
+
+// Prepare creates a prepared statement on the writer pool.
+func (db *DB) Prepare(query string) (*sql.Stmt, error) {
+	return db.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext creates a prepared statement on the writer pool.
+func (db *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return db.writer.PrepareContext(ctx, query)
+}
+
+// Query executes a query on the reader pool.
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query on the reader pool.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.reader.QueryContext(ctx, query, args...)
+}
+
+// QueryRow executes a query on the reader pool that is expected to return at most one row.
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query on the reader pool that is expected to return at most one row.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.reader.QueryRowContext(ctx, query, args...)
+}
+
+// Ping verifies the connections of both pools.
+func (db *DB) Ping() error {
+	return db.PingContext(context.Background())
+}
+
+// PingContext verifies the connections of both pools.
+func (db *DB) PingContext(ctx context.Context) error {
+	if err := db.writer.PingContext(ctx); err != nil {
+		return err
+	}
+	return db.reader.PingContext(ctx)
+}
+
+// Close closes both pools.
+func (db *DB) Close() error {
+	errReader := db.reader.Close()
+	if err := db.writer.Close(); err != nil {
+		return err
+	}
+	return errReader
+}
+
+// Shutdown should be called before the application exits.
+func (db *DB) Shutdown(opts ...ShutdownOption) error {
+	return db.ShutdownContext(context.Background(), opts...)
+}
+
+// ShutdownContext should be called before the application exits.
+//
+// It drains the database in these steps:
+//  1. stop the [sqlite.Maintainer] and reject all new operations with [sqlite.ErrShutdown]
+//  2. wait until all active operations and transactions are finished and all rows are closed, or ctx is done
+//  3. run [sqlite.OptimizeContext] on the writer pool with [sqlite.DefaultShutdownAnalysisLimit],
+//     see [sqlite.WithShutdownAnalysisLimit]
+//  4. close the reader pool and run a [sqlite.CheckpointTruncate] checkpoint on the writer pool
+//  5. close the writer pool
+//
+// Operations within an active transaction are still accepted.
+// If the wait fails, optimize and checkpoint are skipped, but both pools are closed anyway.
+// The returned error joins the errors of all failed steps.
+func (db *DB) ShutdownContext(ctx context.Context, opts ...ShutdownOption) error {
+	cfg := buildShutdownConfig(shutdownConfig{analysisLimit: DefaultShutdownAnalysisLimit}, opts)
+
+	stopMaintenance(db.writer)
+	db.config.activity.stop()
+
+	var errs []error
+	drained := true
+	if err := db.config.activity.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("wait for active operations: %w", err))
+		drained = false
+	}
+
+	ctx = withShutdownOp(ctx)
+	if drained {
+		if err := optimizeWithLimit(ctx, db.writer, cfg.analysisLimit); err != nil {
+			errs = append(errs, fmt.Errorf("optimize: %w", err))
+		}
+	}
+	if err := db.reader.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close reader: %w", err))
+	}
+	if drained {
+		if _, err := Checkpoint(ctx, db.writer, CheckpointTruncate); err != nil {
+			errs = append(errs, fmt.Errorf("checkpoint: %w", err))
+		}
+	}
+	if err := db.writer.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close writer: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,423 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func buildPoolMocks(t *testing.T) (writerMock, readerMock sqlmock.Sqlmock, open sqlOpenFunc) {
+	writer, writerMock := buildMockConnector(t, t.Name()+"/writer")
+	reader, readerMock := buildMockConnector(t, t.Name()+"/reader")
+
+	open = func(_, dsn string) (driver.Connector, error) {
+		if strings.Contains(dsn, "mode=ro") {
+			return reader, nil
+		}
+		return writer, nil
+	}
+	return writerMock, readerMock, open
+}
+
+func Test_buildReaderConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := buildConfig(
+		WithDriver(DriverModernc),
+		WithPath("file:data.db"),
+		WithAutoVacuumMode(AutoVacuumFull),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	reader := buildReaderConfig(config)
+	expected := "file:data.db?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=query_only(1)&_pragma=synchronous(NORMAL)&mode=ro"
+	if reader.DSN != expected {
+		t.Fatalf("expected '%s', got '%s'", expected, reader.DSN)
+	}
+	if config.QueryOnly {
+		t.Fatal("expected writer config to be unchanged")
+	}
+}
+
+func Test_connectPool_InMemory(t *testing.T) {
+	t.Parallel()
+
+	_, err := connectPool(openConnector, WithDriver(DriverModernc))
+	if !errors.Is(err, ErrInMemoryPool) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInMemoryPool, err)
+	}
+}
+
+func Test_connectPool_ErrorWithReader(t *testing.T) {
+	t.Parallel()
+
+	writer, writerMock := buildMockConnector(t, t.Name())
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectClose()
+
+	_, err := connectPool(
+		func(_, dsn string) (driver.Connector, error) {
+			if strings.Contains(dsn, "mode=ro") {
+				return nil, errUnitTest
+			}
+			return writer, nil
+		},
+		WithDriver(DriverModernc),
+		WithPath("file:data.db"),
+	)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_connectPool_Routing(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("INSERT INTO t").WillReturnResult(sqlmock.NewResult(1, 1))
+	writerMock.ExpectBegin()
+	writerMock.ExpectCommit()
+	readerMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	readerMock.ExpectQuery("SELECT 2").WillReturnRows(sqlmock.NewRows([]string{"2"}).AddRow(2))
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverModernc),
+		WithPath("file:data.db"),
+		WithReadConnections(4),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	_ = rows.Close()
+	var got int
+	if err := db.QueryRowContext(ctx, "SELECT 2").Scan(&got); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if got := db.Reader().Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("expected '%d', got '%d'", 4, got)
+	}
+	if got := db.Writer().Stats().MaxOpenConnections; got != 1 {
+		t.Errorf("expected '%d', got '%d'", 1, got)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+func TestDB_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA analysis_limit = 400;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 4, 4))
+	writerMock.ExpectClose()
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := db.Shutdown(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.ExecContext(context.Background(), "INSERT INTO t VALUES (1)"); err == nil {
+		t.Error("expected error after shutdown")
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+func TestDB_ShutdownWithAnalysisLimit(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 4, 4))
+	writerMock.ExpectClose()
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := db.Shutdown(WithShutdownAnalysisLimit(0)); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+// waitForShutdown waits until the shutdown of db rejects new operations.
+func waitForShutdown(t *testing.T, db *DB) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := db.config.activity.begin(context.Background()); errors.Is(err, ErrShutdown) {
+			return
+		}
+		db.config.activity.end()
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected shutdown to be started")
+}
+
+func TestDB_ShutdownDrainsTx(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectBegin()
+	writerMock.ExpectExec("INSERT INTO t").WillReturnResult(sqlmock.NewResult(1, 1))
+	writerMock.ExpectCommit()
+	writerMock.ExpectExec("PRAGMA analysis_limit = 400;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 1, 1))
+	writerMock.ExpectClose()
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- db.ShutdownContext(ctx)
+	}()
+	waitForShutdown(t, db)
+
+	if _, err := db.QueryContext(ctx, "SELECT 1"); !errors.Is(err, ErrShutdown) {
+		t.Errorf("expect error to be '%s', got '%s'", ErrShutdown, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("expected shutdown to wait for the transaction, got '%v'", err)
+	default:
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+func TestDB_ShutdownDrainsRows(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA analysis_limit = 400;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 1, 1))
+	writerMock.ExpectClose()
+	readerMock.ExpectQuery("SELECT id FROM t").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, "SELECT id FROM t")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- db.ShutdownContext(ctx)
+	}()
+	waitForShutdown(t, db)
+
+	var id int
+	if !rows.Next() {
+		t.Fatalf("expected a row, got '%v'", rows.Err())
+	}
+	if err := rows.Scan(&id); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if id != 1 {
+		t.Errorf("expected '%d', got '%d'", 1, id)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("expected shutdown to wait for the rows, got '%v'", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+func TestDB_ShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectBegin()
+	writerMock.ExpectRollback()
+	writerMock.ExpectClose()
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = db.ShutdownContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect error to be '%s', got '%s'", context.DeadlineExceeded, err)
+	}
+	if !strings.Contains(err.Error(), "wait for active operations") {
+		t.Errorf("expected the failed step in '%s'", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
+
+func TestDB_ShutdownJoinsErrors(t *testing.T) {
+	t.Parallel()
+
+	writerMock, readerMock, open := buildPoolMocks(t)
+	writerMock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	writerMock.ExpectExec("PRAGMA analysis_limit = 400;").WillReturnError(errUnitTest)
+	writerMock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).WillReturnError(errUnitTest)
+	writerMock.ExpectClose()
+	readerMock.ExpectClose()
+
+	db, err := connectPool(
+		open,
+		WithDriver(DriverMattn),
+		WithPath("file:data.db"),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	err = db.Shutdown()
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	for _, step := range []string{"optimize: ", "checkpoint: "} {
+		if !strings.Contains(err.Error(), step) {
+			t.Errorf("expected step '%s' in '%s'", step, err)
+		}
+	}
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled writer expectations: %s", err)
+	}
+	if err := readerMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled reader expectations: %s", err)
+	}
+}
@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PragmaMismatch is a pragma which wasn't applied as configured.
+type PragmaMismatch struct {
+	Pragma   string
+	Expected string
+	Actual   string
+}
+
+// PragmaMismatchError will be returned per [sqlite.WithStrictPragmas],
+// if the driver didn't apply all configured pragmas.
+type PragmaMismatchError struct {
+	Mismatches []PragmaMismatch
+}
+
+func (e *PragmaMismatchError) Error() string {
+	mismatches := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		mismatches = append(mismatches, fmt.Sprintf("%s expected '%s', got '%s'", m.Pragma, m.Expected, m.Actual))
+	}
+	return "pragma mismatch: " + strings.Join(mismatches, "; ")
+}
+
+// InspectPragmas will read the live pragma settings of db into a [sqlite.Config].
+//
+// Just the pragmas are set, `case_sensitive_like` can't be read and is always false.
+func InspectPragmas(ctx context.Context, db *sql.DB) (*Config, error) {
+	config := &Config{}
+
+	var autoVacuum, secureDelete, synchronous, tempStore int
+	var cellSizeCheck, deferForeignKeys, foreignKeys, queryOnly, recursiveTriggers bool
+	var journalMode, lockingMode string
+	pragmas := []struct {
+		name string
+		dest any
+	}{
+		{"auto_vacuum", &autoVacuum},
+		{"busy_timeout", &config.BusyTimeout},
+		{"cache_size", &config.CacheSize},
+		{"cell_size_check", &cellSizeCheck},
+		{"defer_foreign_keys", &deferForeignKeys},
+		{"foreign_keys", &foreignKeys},
+		{"journal_mode", &journalMode},
+		{"journal_size_limit", &config.JournalSizeLimit},
+		{"locking_mode", &lockingMode},
+		{"mmap_size", &config.MmapSize},
+		{"page_size", &config.PageSize},
+		{"query_only", &queryOnly},
+		{"recursive_triggers", &recursiveTriggers},
+		{"secure_delete", &secureDelete},
+		{"synchronous", &synchronous},
+		{"temp_store", &tempStore},
+		{"wal_autocheckpoint", &config.WALAutoCheckpoint},
+	}
+	for _, p := range pragmas {
+		err := db.QueryRowContext(ctx, fmt.Sprintf("PRAGMA %s;", p.name)).Scan(p.dest)
+		if errors.Is(err, sql.ErrNoRows) && p.name == "mmap_size" {
+			// In-memory databases don't support memory-mapped I/O and return no row.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pragma '%s': %w", p.name, err)
+		}
+	}
+
+	autoVacuumModes := []AutoVacuumMode{AutoVacuumNone, AutoVacuumFull, AutoVacuumIncremental}
+	if autoVacuum < 0 || autoVacuum >= len(autoVacuumModes) {
+		return nil, fmt.Errorf("pragma 'auto_vacuum': unexpected value '%d'", autoVacuum)
+	}
+	secureDeleteModes := []SecureDelete{SecureDeleteOff, SecureDeleteOn, SecureDeleteFast}
+	if secureDelete < 0 || secureDelete >= len(secureDeleteModes) {
+		return nil, fmt.Errorf("pragma 'secure_delete': unexpected value '%d'", secureDelete)
+	}
+	syncModes := []SyncMode{SyncOff, SyncNormal, SyncFull, SyncExtra}
+	if synchronous < 0 || synchronous >= len(syncModes) {
+		return nil, fmt.Errorf("pragma 'synchronous': unexpected value '%d'", synchronous)
+	}
+	tempStores := []TempStore{TempStoreDefault, TempStoreFile, TempStoreMemory}
+	if tempStore < 0 || tempStore >= len(tempStores) {
+		return nil, fmt.Errorf("pragma 'temp_store': unexpected value '%d'", tempStore)
+	}
+
+	config.AutoVacuumMode = autoVacuumModes[autoVacuum]
+	config.JournalMode = JournalMode(strings.ToUpper(journalMode))
+	config.LockingMode = LockingMode(strings.ToUpper(lockingMode))
+	config.SecureDelete = secureDeleteModes[secureDelete]
+	config.SyncMode = syncModes[synchronous]
+	config.TempStore = tempStores[tempStore]
+	config.CellSizeCheck = cellSizeCheck
+	config.DeferForeignKeys = deferForeignKeys
+	config.ForeignKey = foreignKeys
+	config.QueryOnly = queryOnly
+	config.RecursiveTriggers = recursiveTriggers
+
+	return config, nil
+}
+
+// verifyPragmas compares all configured pragmas with the live settings of db.
+func verifyPragmas(ctx context.Context, db *sql.DB, config *Config) error {
+	actual, err := InspectPragmas(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []PragmaMismatch
+	check := func(pragma string, expected, got any) {
+		if expected != got {
+			mismatches = append(mismatches, PragmaMismatch{
+				Pragma:   pragma,
+				Expected: fmt.Sprint(expected),
+				Actual:   fmt.Sprint(got),
+			})
+		}
+	}
+
+	if config.AutoVacuumMode != AutoVacuumDefault {
+		check("auto_vacuum", config.AutoVacuumMode, actual.AutoVacuumMode)
+	}
+	if config.BusyTimeout > 0 {
+		check("busy_timeout", config.BusyTimeout, actual.BusyTimeout)
+	}
+	if config.CacheSize != 0 {
+		check("cache_size", config.CacheSize, actual.CacheSize)
+	}
+	if config.CellSizeCheck {
+		check("cell_size_check", true, actual.CellSizeCheck)
+	}
+	// defer_foreign_keys is reset by SQLite after every transaction and can't be verified.
+	if config.ForeignKey {
+		check("foreign_keys", true, actual.ForeignKey)
+	}
+	if config.JournalMode != JournalDefault {
+		check("journal_mode", config.JournalMode, actual.JournalMode)
+	}
+	if config.JournalSizeLimit > 0 {
+		check("journal_size_limit", config.JournalSizeLimit, actual.JournalSizeLimit)
+	}
+	if config.LockingMode != LockingDefault {
+		check("locking_mode", config.LockingMode, actual.LockingMode)
+	}
+	if config.MmapSize > 0 {
+		check("mmap_size", config.MmapSize, actual.MmapSize)
+	}
+	if config.PageSize > 0 {
+		check("page_size", config.PageSize, actual.PageSize)
+	}
+	if config.QueryOnly {
+		check("query_only", true, actual.QueryOnly)
+	}
+	if config.RecursiveTriggers {
+		check("recursive_triggers", true, actual.RecursiveTriggers)
+	}
+	if config.SecureDelete != SecureDeleteDefault {
+		check("secure_delete", config.SecureDelete, actual.SecureDelete)
+	}
+	if config.SyncMode != SyncDefault {
+		check("synchronous", config.SyncMode, actual.SyncMode)
+	}
+	if config.TempStore != TempStoreDefault {
+		check("temp_store", config.TempStore, actual.TempStore)
+	}
+	if config.WALAutoCheckpoint > 0 {
+		check("wal_autocheckpoint", config.WALAutoCheckpoint, actual.WALAutoCheckpoint)
+	} else if config.WALAutoCheckpoint < 0 {
+		check("wal_autocheckpoint", 0, actual.WALAutoCheckpoint)
+	}
+
+	if len(mismatches) > 0 {
+		return &PragmaMismatchError{Mismatches: mismatches}
+	}
+	return nil
+}
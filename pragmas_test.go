@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectPragmas(mock sqlmock.Sqlmock, autoVacuum, busyTimeout int, journalMode string, synchronous int) {
+	values := []struct {
+		name  string
+		value any
+	}{
+		{"auto_vacuum", autoVacuum},
+		{"busy_timeout", busyTimeout},
+		{"cache_size", -2000},
+		{"cell_size_check", 0},
+		{"defer_foreign_keys", 0},
+		{"foreign_keys", 1},
+		{"journal_mode", journalMode},
+		{"journal_size_limit", 100000000},
+		{"locking_mode", "normal"},
+		{"mmap_size", 0},
+		{"page_size", 4096},
+		{"query_only", 0},
+		{"recursive_triggers", 0},
+		{"secure_delete", 0},
+		{"synchronous", synchronous},
+		{"temp_store", 0},
+		{"wal_autocheckpoint", 1000},
+	}
+	for _, v := range values {
+		mock.ExpectQuery("PRAGMA " + v.name + ";").
+			WillReturnRows(sqlmock.NewRows([]string{v.name}).AddRow(v.value))
+	}
+}
+
+func TestInspectPragmas(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	expectPragmas(mock, 2, 4000, "wal", 1)
+
+	config, err := InspectPragmas(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.AutoVacuumMode != AutoVacuumIncremental {
+		t.Errorf("expected '%s', got '%s'", AutoVacuumIncremental, config.AutoVacuumMode)
+	}
+	if config.BusyTimeout != 4000 {
+		t.Errorf("expected '%d', got '%d'", 4000, config.BusyTimeout)
+	}
+	if !config.ForeignKey || config.DeferForeignKeys || config.QueryOnly {
+		t.Errorf("unexpected foreign key or query only settings '%+v'", config)
+	}
+	if config.JournalMode != JournalWAL {
+		t.Errorf("expected '%s', got '%s'", JournalWAL, config.JournalMode)
+	}
+	if config.JournalSizeLimit != 100000000 {
+		t.Errorf("expected '%d', got '%d'", 100000000, config.JournalSizeLimit)
+	}
+	if config.SyncMode != SyncNormal {
+		t.Errorf("expected '%s', got '%s'", SyncNormal, config.SyncMode)
+	}
+	if config.LockingMode != LockingNormal {
+		t.Errorf("expected '%s', got '%s'", LockingNormal, config.LockingMode)
+	}
+	if config.SecureDelete != SecureDeleteOff {
+		t.Errorf("expected '%s', got '%s'", SecureDeleteOff, config.SecureDelete)
+	}
+	if config.TempStore != TempStoreDefault {
+		t.Errorf("expected '%s', got '%s'", TempStoreDefault, config.TempStore)
+	}
+	if config.CacheSize != -2000 || config.PageSize != 4096 || config.WALAutoCheckpoint != 1000 {
+		t.Errorf("unexpected sizes '%+v'", config)
+	}
+}
+
+func TestInspectPragmas_WithError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("PRAGMA auto_vacuum;").WillReturnError(errUnitTest)
+
+	_, err = InspectPragmas(context.Background(), db)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+}
+
+func TestInspectPragmas_UnexpectedValue(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	expectPragmas(mock, 0, 0, "wal", 7)
+
+	if _, err = InspectPragmas(context.Background(), db); err == nil {
+		t.Fatal("expected an error for an unknown synchronous value")
+	}
+}
+
+func Test_connect_StrictPragmas(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectPragmas(mock, 0, 4000, "wal", 1)
+
+	_, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithStrictPragmas(),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_connect_StrictPragmasMismatch(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectPragmas(mock, 0, 4000, "memory", 2)
+	mock.ExpectClose()
+
+	_, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithStrictPragmas(),
+	)
+
+	var mismatchErr *PragmaMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("expect error to be '%T', got '%s'", mismatchErr, err)
+	}
+	expected := []PragmaMismatch{
+		{Pragma: "journal_mode", Expected: "WAL", Actual: "MEMORY"},
+		{Pragma: "synchronous", Expected: "NORMAL", Actual: "FULL"},
+	}
+	if len(mismatchErr.Mismatches) != len(expected) {
+		t.Fatalf("expected '%v', got '%v'", expected, mismatchErr.Mismatches)
+	}
+	for i := range expected {
+		if mismatchErr.Mismatches[i] != expected[i] {
+			t.Errorf("expected '%v', got '%v'", expected[i], mismatchErr.Mismatches[i])
+		}
+	}
+	expectedMsg := "pragma mismatch: journal_mode expected 'WAL', got 'MEMORY'; synchronous expected 'NORMAL', got 'FULL'"
+	if err.Error() != expectedMsg {
+		t.Errorf("expected '%s', got '%s'", expectedMsg, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// Preset is a named set of pragmas for a typical workload, use it per [sqlite.WithPreset].
+type Preset string
+
+// The available presets of [sqlite.WithPreset].
+//
+// Every preset starts from the pragmas of [sqlite.DefaultConfig], pragmas which aren't listed are not set,
+// so the default of SQLite is used:
+//
+//   - PresetServer: journal_mode=WAL, synchronous=NORMAL, busy_timeout=4000, journal_size_limit=100000000,
+//     foreign_keys=1, the defaults of [sqlite.Connect].
+//   - PresetBulkImport: journal_mode=OFF, synchronous=OFF, cache_size=-262144 (256 MiB), locking_mode=EXCLUSIVE,
+//     temp_store=MEMORY, busy_timeout=4000, foreign_keys=1. A crash during the import can corrupt the database.
+//   - PresetReadOnlyAnalytics: the path is opened with mode=ro and immutable=1, query_only=1,
+//     mmap_size=268435456 (256 MiB), cache_size=-65536 (64 MiB), temp_store=MEMORY, busy_timeout=4000,
+//     foreign_keys=1. The connections are not limited, so queries run in parallel.
+//     The database file must not be changed while it's open.
+//   - PresetEmbeddedDevice: journal_mode=WAL, synchronous=FULL, cache_size=-1024 (1 MiB), auto_vacuum=INCREMENTAL,
+//     journal_size_limit=4194304, busy_timeout=4000, foreign_keys=1. auto_vacuum only changes a new database.
+//   - PresetTest: a new [sqlite.SharedMemory] database for every connect, synchronous=OFF, busy_timeout=4000,
+//     foreign_keys=1. A file path is rejected with [sqlite.ErrIncompatibleConfig], [sqlite.MemoryPath] is kept.
+//     The database is destroyed, when its last connection is closed, so keep the default connection limits
+//     or use [sqlite.ConnectMemory].
+const (
+	PresetServer            Preset = "server"
+	PresetBulkImport        Preset = "bulk_import"
+	PresetReadOnlyAnalytics Preset = "read_only_analytics"
+	PresetEmbeddedDevice    Preset = "embedded_device"
+	PresetTest              Preset = "test"
+)
+
+// ErrUnknownPreset will be returned by [sqlite.WithPreset] for an unknown [sqlite.Preset].
+var ErrUnknownPreset = errors.New("unknown preset")
+
+// presets changes the pragmas of a config, which starts with the pragmas of [sqlite.newConfig].
+var presets = map[Preset]func(c *Config){
+	PresetServer: func(*Config) {},
+	PresetBulkImport: func(c *Config) {
+		c.CacheSize = -262144
+		c.JournalMode = JournalOff
+		c.JournalSizeLimit = 0
+		c.LockingMode = LockingExclusive
+		c.SyncMode = SyncOff
+		c.TempStore = TempStoreMemory
+	},
+	PresetReadOnlyAnalytics: func(c *Config) {
+		c.LimitConnection = false
+
+		c.CacheSize = -65536
+		c.JournalMode = JournalDefault
+		c.JournalSizeLimit = 0
+		c.MmapSize = 268435456
+		c.QueryOnly = true
+		c.SyncMode = SyncDefault
+		c.TempStore = TempStoreMemory
+	},
+	PresetEmbeddedDevice: func(c *Config) {
+		c.AutoVacuumMode = AutoVacuumIncremental
+		c.CacheSize = -1024
+		c.JournalSizeLimit = 4194304
+		c.SyncMode = SyncFull
+	},
+	PresetTest: func(c *Config) {
+		if c.Path == "" {
+			c.Path = presetMemoryPath()
+		}
+
+		c.JournalMode = JournalDefault
+		c.JournalSizeLimit = 0
+		c.SyncMode = SyncOff
+	},
+}
+
+// presetTestDBs counts the in-memory databases of [sqlite.PresetTest], so every connect gets its own database.
+var presetTestDBs atomic.Uint64
+
+// applyPreset replaces all pragmas and the connection limit of c with the ones of preset.
+func applyPreset(c *Config, preset Preset) error {
+	apply, ok := presets[preset]
+	if !ok {
+		return fmt.Errorf("given '%s', %w", preset, ErrUnknownPreset)
+	}
+	defaults := newConfig()
+	copyPragmas(c, defaults)
+	c.LimitConnection = defaults.LimitConnection
+	c.preset = preset
+	apply(c)
+	return nil
+}
+
+// copyPragmas sets all pragmas of dst to the ones of src.
+func copyPragmas(dst, src *Config) {
+	dst.AutoVacuumMode = src.AutoVacuumMode
+	dst.BusyTimeout = src.BusyTimeout
+	dst.CacheSize = src.CacheSize
+	dst.CaseSensitiveLike = src.CaseSensitiveLike
+	dst.CellSizeCheck = src.CellSizeCheck
+	dst.DeferForeignKeys = src.DeferForeignKeys
+	dst.ForeignKey = src.ForeignKey
+	dst.JournalMode = src.JournalMode
+	dst.JournalSizeLimit = src.JournalSizeLimit
+	dst.LockingMode = src.LockingMode
+	dst.MmapSize = src.MmapSize
+	dst.PageSize = src.PageSize
+	dst.QueryOnly = src.QueryOnly
+	dst.RecursiveTriggers = src.RecursiveTriggers
+	dst.SecureDelete = src.SecureDelete
+	dst.SyncMode = src.SyncMode
+	dst.TempStore = src.TempStore
+	dst.WALAutoCheckpoint = src.WALAutoCheckpoint
+}
+
+// presetPath returns the path of config changed per the preset, before the config is validated.
+//
+// [sqlite.PresetReadOnlyAnalytics] opens a file with mode=ro and immutable=1, an invalid path is left to the validation.
+func presetPath(config *Config) string {
+	if config.preset != PresetReadOnlyAnalytics || isMemoryPath(config.Path) || validatePath(config.Path) != nil {
+		return config.Path
+	}
+	name, query, _ := strings.Cut(config.Path, "?")
+	if !strings.HasPrefix(name, "file:") {
+		name = FilePath(name).String()
+	}
+	values, _ := url.ParseQuery(query)
+	values.Set("mode", string(OpenReadOnly))
+	values.Set("immutable", "1")
+	return name + "?" + encodeQuery(values)
+}
+
+// presetMemoryPath returns the path of a new shared in-memory database for [sqlite.PresetTest].
+func presetMemoryPath() string {
+	return SharedMemory(fmt.Sprintf("sqlite-test-%d", presetTestDBs.Add(1))).String()
+}
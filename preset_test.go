@@ -0,0 +1,201 @@
+package sqlite
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithPreset_DSN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		preset       Preset
+		driver       Driver
+		expected     string
+		connPragmas  []string
+		disableLimit bool
+	}{
+		{
+			PresetServer, DriverMattn,
+			"data.db?_fk=true&_journal=WAL&_sync=1&_timeout=4000",
+			[]string{"PRAGMA journal_size_limit = 100000000;"}, false,
+		},
+		{
+			PresetServer, DriverModernc,
+			"data.db?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)",
+			[]string{"PRAGMA journal_size_limit = 100000000;"}, false,
+		},
+		{
+			PresetBulkImport, DriverMattn,
+			"data.db?_cache_size=-262144&_fk=true&_journal=OFF&_locking_mode=EXCLUSIVE&_sync=0&_timeout=4000",
+			[]string{"PRAGMA temp_store = MEMORY;"}, false,
+		},
+		{
+			PresetBulkImport, DriverModernc,
+			"data.db?_pragma=busy_timeout(4000)&_pragma=cache_size(-262144)&_pragma=foreign_keys(1)&_pragma=journal_mode(OFF)" +
+				"&_pragma=locking_mode(EXCLUSIVE)&_pragma=synchronous(OFF)&_pragma=temp_store(MEMORY)",
+			nil, false,
+		},
+		{
+			PresetReadOnlyAnalytics, DriverMattn,
+			"file:data.db?_cache_size=-65536&_fk=true&_query_only=true&_timeout=4000&immutable=1&mode=ro",
+			[]string{"PRAGMA mmap_size = 268435456;", "PRAGMA temp_store = MEMORY;"}, true,
+		},
+		{
+			PresetReadOnlyAnalytics, DriverModernc,
+			"file:data.db?_pragma=busy_timeout(4000)&_pragma=cache_size(-65536)&_pragma=foreign_keys(1)&_pragma=mmap_size(268435456)" +
+				"&_pragma=query_only(1)&_pragma=temp_store(MEMORY)&immutable=1&mode=ro",
+			nil, true,
+		},
+		{
+			PresetEmbeddedDevice, DriverMattn,
+			"data.db?_auto_vacuum=2&_cache_size=-1024&_fk=true&_journal=WAL&_sync=2&_timeout=4000",
+			[]string{"PRAGMA journal_size_limit = 4194304;"}, false,
+		},
+		{
+			PresetEmbeddedDevice, DriverModernc,
+			"data.db?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(4000)&_pragma=cache_size(-1024)&_pragma=foreign_keys(1)" +
+				"&_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)",
+			[]string{"PRAGMA journal_size_limit = 4194304;"}, false,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(string(tc.preset)+" "+string(tc.driver), func(t *testing.T) {
+			t.Parallel()
+
+			config, err := buildConfig(
+				WithDriver(tc.driver),
+				WithPath("data.db"),
+				WithPreset(tc.preset),
+			)
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if config.DSN != tc.expected {
+				t.Errorf("expected '%s', got '%s'", tc.expected, config.DSN)
+			}
+			if got := connPragmas(config); !reflect.DeepEqual(got, tc.connPragmas) {
+				t.Errorf("expected '%v', got '%v'", tc.connPragmas, got)
+			}
+			if config.LimitConnection == tc.disableLimit {
+				t.Errorf("expected '%v', got '%v'", !tc.disableLimit, config.LimitConnection)
+			}
+		})
+	}
+}
+
+func TestWithPreset_Test(t *testing.T) {
+	t.Parallel()
+
+	first, err := buildConfig(WithDriver(DriverModernc), WithPreset(PresetTest))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	second, err := buildConfig(WithDriver(DriverMattn), WithPreset(PresetTest))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	ncruces, err := buildConfig(WithDriver(DriverNcruces), WithPreset(PresetTest))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	if !isMemoryPath(first.Path) || !strings.Contains(first.Path, "cache=shared") {
+		t.Errorf("expected a shared in-memory path, got '%s'", first.Path)
+	}
+	if first.Path == second.Path {
+		t.Errorf("expected a new database, got '%s' twice", first.Path)
+	}
+	expected := "_fk=true&_sync=0&_timeout=4000&cache=shared&mode=memory"
+	if _, query, _ := strings.Cut(second.DSN, "?"); query != expected {
+		t.Errorf("expected '%s', got '%s'", expected, query)
+	}
+	if !strings.HasSuffix(ncruces.Path, "?vfs=memdb") {
+		t.Errorf("expected a memdb path, got '%s'", ncruces.Path)
+	}
+}
+
+func TestWithPreset_TestFilePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"PathBefore", []Option{WithPath("data.db"), WithPreset(PresetTest)}},
+		{"PathAfter", []Option{WithPreset(PresetTest), WithPath("file:data.db")}},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := buildConfig(append([]Option{WithDriver(DriverModernc)}, tc.opts...)...)
+			if !errors.Is(err, ErrIncompatibleConfig) {
+				t.Fatalf("expect error to be '%s', got '%s'", ErrIncompatibleConfig, err)
+			}
+		})
+	}
+
+	config, err := buildConfig(WithDriver(DriverModernc), WithPreset(PresetTest), WithPath(MemoryPath))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if config.Path != MemoryPath {
+		t.Errorf("expected '%s', got '%s'", MemoryPath, config.Path)
+	}
+}
+
+func TestWithPreset_ReadOnlyAnalyticsPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"data.db", "file:data.db?immutable=1&mode=ro"},
+		{"file:/data.db?cache=private", "file:/data.db?cache=private&immutable=1&mode=ro"},
+		{MemoryPath, MemoryPath},
+		{"", MemoryPath},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run("With path '"+tc.path+"'", func(t *testing.T) {
+			t.Parallel()
+
+			config, err := buildConfig(WithDriver(DriverModernc), WithPath(tc.path), WithPreset(PresetReadOnlyAnalytics))
+			if err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if config.Path != tc.expected {
+				t.Errorf("expected '%s', got '%s'", tc.expected, config.Path)
+			}
+		})
+	}
+}
+
+func TestWithPreset_ReadOnlyAnalyticsWAL(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(
+		WithDriver(DriverModernc),
+		WithPath("data.db"),
+		WithPreset(PresetReadOnlyAnalytics),
+		WithJournalMode(JournalWAL),
+	)
+	if !errors.Is(err, ErrIncompatibleConfig) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrIncompatibleConfig, err)
+	}
+}
+
+func TestWithPreset_Unknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(WithDriver(DriverModernc), WithPreset("fast"))
+	if !errors.Is(err, ErrUnknownPreset) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrUnknownPreset, err)
+	}
+}
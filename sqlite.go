@@ -4,6 +4,8 @@
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"sync/atomic"
 )
 
 // ErrCantDetectDriver will be returned if no specific [sqlite.Driver] was set per [sqlite.WithDriver] and
@@ -15,26 +17,36 @@
 //		  _ "github.com/mattn/go-sqlite3"
 //		  // or
 //		  _ "modernc.org/sqlite"
+//		  // or
+//		  _ "github.com/ncruces/go-sqlite3/driver"
 //	  )
 var ErrCantDetectDriver = errors.New("cannot detect the correct driver")
 
+// ErrUnknownDriver will be returned if no [sqlite.DriverAdapter] is registered for the [sqlite.Driver]
+// set per [sqlite.WithDriver].
+var ErrUnknownDriver = errors.New("unknown driver")
+
 // ErrInvalidPath will be returned if the provided path is invalid.
 var ErrInvalidPath = errors.New("invalid path provided")
 
-var openFunc sqlOpenFunc = sql.Open
+var openFunc sqlOpenFunc = openConnector
 
 // Connect will connect to a SQLite database with some typical performance settings and foreign key support.
 //
 // You should call Shutdown / ShutdownContext on defer or within a shutdown hook.
-// You should call after some hours Optimize / OptimizeContext on a regular basis.
+// You should call after some hours Optimize / OptimizeContext on a regular basis or use [sqlite.WithMaintenance].
 //
 // If no [sqlite.Driver] is manually set per [sqlite.WithDriver], [sqlite.Connect] tries to detect a [sqlite.Driver].
-// The detected [sqlite.Driver] is based on the registered sql drivers:
+// The detected [sqlite.Driver] is based on the registered sql drivers, see [sqlite.DetectedDrivers]:
 //   - "sqlite" for "modernc.org/sqlite"
-//   - "sqlite3" for "github.com/mattn/go-sqlite3"
+//   - "sqlite3" for "github.com/mattn/go-sqlite3" or "github.com/ncruces/go-sqlite3"
+//
+// Use [sqlite.WithDriverPreference] or the environment variable [sqlite.EnvDriver], if multiple drivers are registered.
+// [sql.DB.Driver] and [sql.Conn.Raw] return the driver and its connections wrapped for this package,
+// use [sqlite.UnwrapDriver] and [sqlite.UnwrapConn] for type assertions to the types of the driver.
 //
 // These are the default Settings]:
-//   - Path is ":memory" for an in-memory sqlite connection
+//   - Path is [sqlite.MemoryPath] for an in-memory sqlite connection
 //   - JournalMode WAL
 //   - SyncMode NORMAL
 //   - Sets on sql.DB the connection limit to 1 and lifetime to 0
@@ -59,19 +71,91 @@ func OptimizeContext(ctx context.Context, db *sql.DB) error {
 	if _, err := db.ExecContext(ctx, "PRAGMA optimize;"); err != nil {
 		return err
 	}
+	countDBMetric(db, func(m *dbMetrics) *atomic.Int64 { return &m.optimizes })
+	return nil
+}
+
+// DefaultShutdownAnalysisLimit is the `PRAGMA analysis_limit` of the final optimize of [sqlite.DB.ShutdownContext].
+const DefaultShutdownAnalysisLimit = 400
+
+// optimizeWithLimit runs [sqlite.OptimizeContext] with `PRAGMA analysis_limit` set to limit on the same connection.
+func optimizeWithLimit(ctx context.Context, db *sql.DB, limit int) error {
+	if limit <= 0 {
+		return OptimizeContext(ctx, db)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA analysis_limit = %d;", limit)); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA optimize;"); err != nil {
+		return err
+	}
+	countDBMetric(db, func(m *dbMetrics) *atomic.Int64 { return &m.optimizes })
 	return nil
 }
 
+// ShutdownOption is a func to configure [sqlite.ShutdownContext].
+type ShutdownOption func(c *shutdownConfig)
+
+type shutdownConfig struct {
+	checkpoint    bool
+	analysisLimit int
+}
+
+// WithShutdownCheckpoint will run a [sqlite.CheckpointTruncate] checkpoint after the final optimize,
+// so no `-wal` and `-shm` files are left behind.
+// [sqlite.DB.ShutdownContext] always runs it.
+func WithShutdownCheckpoint() ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.checkpoint = true
+	}
+}
+
+// WithShutdownAnalysisLimit will set `PRAGMA analysis_limit` for the final optimize,
+// so it doesn't scan large tables completely. A limit <= 0 keeps the limit of the connection.
+//
+// See https://www.sqlite.org/pragma.html#pragma_analysis_limit.
+func WithShutdownAnalysisLimit(limit int) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.analysisLimit = limit
+	}
+}
+
+func buildShutdownConfig(defaults shutdownConfig, opts []ShutdownOption) *shutdownConfig {
+	cfg := &defaults
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
 // Shutdown should be called before the application exits.
-func Shutdown(db *sql.DB) error {
-	return ShutdownContext(context.Background(), db)
+func Shutdown(db *sql.DB, opts ...ShutdownOption) error {
+	return ShutdownContext(context.Background(), db, opts...)
 }
 
 // ShutdownContext should be called before the application exits.
-func ShutdownContext(ctx context.Context, db *sql.DB) error {
-	if err := OptimizeContext(ctx, db); err != nil {
+//
+// A [sqlite.Maintainer] started for db is stopped before the final optimize.
+// The final optimize uses the `PRAGMA analysis_limit` of the connection, unless [sqlite.WithShutdownAnalysisLimit] is set.
+func ShutdownContext(ctx context.Context, db *sql.DB, opts ...ShutdownOption) error {
+	cfg := buildShutdownConfig(shutdownConfig{}, opts)
+
+	stopMaintenance(db)
+	if err := optimizeWithLimit(ctx, db, cfg.analysisLimit); err != nil {
 		return err
 	}
+	if cfg.checkpoint {
+		if _, err := Checkpoint(ctx, db, CheckpointTruncate); err != nil {
+			return err
+		}
+	}
 	if err := db.Close(); err != nil {
 		return err
 	}
@@ -96,5 +180,6 @@ func VacuumContext(ctx context.Context, db *sql.DB) error {
 	if _, err := db.ExecContext(ctx, "VACUUM;"); err != nil {
 		return err
 	}
+	countDBMetric(db, func(m *dbMetrics) *atomic.Int64 { return &m.vacuums })
 	return nil
 }
@@ -1,114 +1,241 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
 	_ "unsafe" // For go:linkname
-)
-
-func buildDSN(path string, params []string) string {
-	dsn := path
-	if len(params) == 0 {
-		return dsn
-	}
-
-	dsn += "?" + params[0]
-	for _, p := range params[1:] {
-		dsn += "&" + p
-	}
 
-	return dsn
-}
+	"github.com/lanz-dev/go-sqlite/migrate"
+)
 
 // buildMattnDSN works with "github.com/mattn/go-sqlite3"
 func buildMattnDSN(config *Config) string {
-	var params []string
+	params := url.Values{}
 
 	if config.AutoVacuumMode != AutoVacuumDefault {
 		mode := config.AutoVacuumMode.Int()
 		if mode != -99 {
-			params = append(params, fmt.Sprintf("_auto_vacuum=%d", mode))
+			params.Add("_auto_vacuum", strconv.Itoa(mode))
 		}
 	}
 	if config.BusyTimeout > 0 {
-		params = append(params, fmt.Sprintf("_timeout=%d", config.BusyTimeout))
+		params.Add("_timeout", strconv.Itoa(config.BusyTimeout))
+	}
+	if config.CacheSize != 0 {
+		params.Add("_cache_size", strconv.Itoa(config.CacheSize))
 	}
 	if config.CaseSensitiveLike {
-		params = append(params, "_case_sensitive_like=true")
+		params.Add("_case_sensitive_like", "true")
 	}
 	if config.ForeignKey {
-		params = append(params, "_fk=true")
+		params.Add("_fk", "true")
 		if config.DeferForeignKeys {
-			params = append(params, "defer_foreign_keys=true")
+			params.Add("_defer_foreign_keys", "true")
 		}
 	}
-	if config.JournalMode != JournalDefault {
-		params = append(params, fmt.Sprintf("_journal=%s", config.JournalMode))
+	if config.JournalMode != JournalDefault && config.PageSize <= 0 {
+		params.Add("_journal", string(config.JournalMode))
+	}
+	if config.LockingMode != LockingDefault {
+		params.Add("_locking_mode", string(config.LockingMode))
+	}
+	if config.QueryOnly {
+		params.Add("_query_only", "true")
+	}
+	if config.RecursiveTriggers {
+		params.Add("_recursive_triggers", "true")
+	}
+	if config.SecureDelete != SecureDeleteDefault {
+		params.Add("_secure_delete", string(config.SecureDelete))
 	}
 	if config.SyncMode != SyncDefault {
 		mode := config.SyncMode.Int()
 		if mode != -99 {
-			params = append(params, fmt.Sprintf("_sync=%d", mode))
+			params.Add("_sync", strconv.Itoa(mode))
 		}
 	}
+	if config.TxLock != TxDefault {
+		params.Add("_txlock", strings.ToLower(string(config.TxLock)))
+	}
 
-	return buildDSN(config.Path, params)
+	return encodeDSN(config.Path, params)
 }
 
 // buildModerncDSN works with "modernc.org/sqlite"
 func buildModerncDSN(config *Config) string {
-	var params []string
+	params := url.Values{}
 
 	if config.AutoVacuumMode != AutoVacuumDefault {
-		params = append(params, fmt.Sprintf("_pragma=auto_vacuum(%s)", config.AutoVacuumMode))
+		params.Add("_pragma", fmt.Sprintf("auto_vacuum(%s)", config.AutoVacuumMode))
 	}
 	if config.BusyTimeout > 0 {
-		params = append(params, fmt.Sprintf("_pragma=busy_timeout(%d)", config.BusyTimeout))
+		params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", config.BusyTimeout))
+	}
+	if config.CacheSize != 0 {
+		params.Add("_pragma", fmt.Sprintf("cache_size(%d)", config.CacheSize))
 	}
 	if config.CaseSensitiveLike {
-		params = append(params, "_pragma=case_sensitive_like(1)")
+		params.Add("_pragma", "case_sensitive_like(1)")
+	}
+	if config.CellSizeCheck {
+		params.Add("_pragma", "cell_size_check(1)")
 	}
 	if config.ForeignKey {
-		params = append(params, "_pragma=foreign_keys(1)")
+		params.Add("_pragma", "foreign_keys(1)")
 		if config.DeferForeignKeys {
-			params = append(params, "_pragma=defer_foreign_keys(1)")
+			params.Add("_pragma", "defer_foreign_keys(1)")
 		}
 	}
-	if config.JournalMode != JournalDefault {
-		params = append(params, fmt.Sprintf("_pragma=journal_mode(%s)", config.JournalMode))
+	if config.JournalMode != JournalDefault && config.PageSize <= 0 {
+		params.Add("_pragma", fmt.Sprintf("journal_mode(%s)", config.JournalMode))
+	}
+	if config.LockingMode != LockingDefault {
+		params.Add("_pragma", fmt.Sprintf("locking_mode(%s)", config.LockingMode))
+	}
+	if config.MmapSize > 0 {
+		params.Add("_pragma", fmt.Sprintf("mmap_size(%d)", config.MmapSize))
+	}
+	if config.QueryOnly {
+		params.Add("_pragma", "query_only(1)")
+	}
+	if config.RecursiveTriggers {
+		params.Add("_pragma", "recursive_triggers(1)")
+	}
+	if config.SecureDelete != SecureDeleteDefault {
+		params.Add("_pragma", fmt.Sprintf("secure_delete(%s)", config.SecureDelete))
 	}
 	if config.SyncMode != SyncDefault {
-		params = append(params, fmt.Sprintf("_pragma=synchronous(%s)", config.SyncMode))
+		params.Add("_pragma", fmt.Sprintf("synchronous(%s)", config.SyncMode))
+	}
+	if config.TempStore != TempStoreDefault {
+		params.Add("_pragma", fmt.Sprintf("temp_store(%s)", config.TempStore))
+	}
+	if config.WALAutoCheckpoint != 0 {
+		params.Add("_pragma", fmt.Sprintf("wal_autocheckpoint(%d)", config.WALAutoCheckpoint))
+	}
+	if config.TxLock != TxDefault {
+		params.Add("_txlock", strings.ToLower(string(config.TxLock)))
 	}
 
-	return buildDSN(config.Path, params)
+	return encodeDSN(config.Path, params)
 }
 
-func detectDriver() (Driver, error) {
-	for _, d := range sql.Drivers() {
-		if d == DriverNameModernc {
-			return DriverModernc, nil
-		}
-		if d == DriverNameMattn {
-			return DriverMattn, nil
+// buildDriverDSN builds the DSN with the [sqlite.DriverAdapter] of config.Driver.
+func buildDriverDSN(config *Config) string {
+	if adapter, ok := lookupDriverAdapter(config.Driver); ok {
+		return adapter.BuildDSN(config)
+	}
+	return ""
+}
+
+// connPragmas returns the pragma statements, which can't be set per DSN for config.Driver.
+//
+// The pragmas of the [sqlite.DriverAdapter] of config.Driver follow the pragmas needed by every driver.
+func connPragmas(config *Config) []string {
+	var pragmas []string
+
+	// The page size must be set before the journal mode, because the journal mode creates the database.
+	// Neither driver keeps the order of the DSN pragmas, so both are set here.
+	if config.PageSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA page_size = %d;", config.PageSize))
+		if config.JournalMode != JournalDefault {
+			pragmas = append(pragmas, fmt.Sprintf("PRAGMA journal_mode = %s;", config.JournalMode))
 		}
 	}
-	return "", ErrCantDetectDriver
+	if config.JournalSizeLimit > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA journal_size_limit = %d;", config.JournalSizeLimit))
+	}
+	if adapter, ok := lookupDriverAdapter(config.Driver); ok {
+		pragmas = append(pragmas, adapter.ConnPragmas(config)...)
+	}
+
+	return pragmas
 }
 
-type sqlOpenFunc func(driverName, dataSourceName string) (*sql.DB, error)
+// buildMattnConnPragmas returns the pragma statements, which "github.com/mattn/go-sqlite3" has no DSN parameter for.
+func buildMattnConnPragmas(config *Config) []string {
+	var pragmas []string
+
+	if config.CellSizeCheck {
+		pragmas = append(pragmas, "PRAGMA cell_size_check = 1;")
+	}
+	if config.MmapSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA mmap_size = %d;", config.MmapSize))
+	}
+	if config.TempStore != TempStoreDefault {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA temp_store = %s;", config.TempStore))
+	}
+	if config.WALAutoCheckpoint != 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA wal_autocheckpoint = %d;", config.WALAutoCheckpoint))
+	}
+
+	return pragmas
+}
+
+// connInits returns the init funcs run on every new connection, the pragmas of [sqlite.connPragmas] first.
+func connInits(config *Config) []ConnInitFunc {
+	var inits []ConnInitFunc
+	if pragmas := connPragmas(config); len(pragmas) > 0 {
+		inits = append(inits, execInit(pragmas))
+	}
+	return append(inits, config.ConnInit...)
+}
 
 func connect(openFunc sqlOpenFunc, opts ...Option) (*sql.DB, error) {
 	config, err := buildConfig(opts...)
 	if err != nil {
 		return nil, err
 	}
+	return openConfig(openFunc, config)
+}
 
-	db, err := openFunc(config.DriverName, config.DSN)
+// openConfig opens the database of config and starts the maintenance.
+func openConfig(openFunc sqlOpenFunc, config *Config) (*sql.DB, error) {
+	db, err := openDB(openFunc, config)
 	if err != nil {
 		return nil, err
 	}
+
+	if config.Maintenance != nil {
+		StartMaintenance(context.Background(), db, buildMaintenanceConfig(config))
+	}
+
+	return db, nil
+}
+
+func openDB(openFunc sqlOpenFunc, config *Config) (*sql.DB, error) {
+	if config.CreateDirs {
+		if err := createDirs(config.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	connector, err := openFunc(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, err
+	}
+	ic := &initConnector{
+		connector: connector,
+		init:      connInits(config),
+		hook:      buildHook(config.QueryHooks),
+		activity:  config.activity,
+		metrics:   &dbMetrics{path: config.Path},
+	}
+	connector = ic
+	for _, wrap := range config.ConnectorWrappers {
+		connector = wrap(config, connector)
+	}
+	db := sql.OpenDB(connector)
+	ic.onClose = func() {
+		stopMaintenance(db)
+	}
 	if err := db.Ping(); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
@@ -119,12 +246,35 @@ func connect(openFunc sqlOpenFunc, opts ...Option) (*sql.DB, error) {
 		db.SetConnMaxIdleTime(0)
 	}
 
-	if config.JournalSizeLimit > 0 {
-		_, err = db.Exec(fmt.Sprintf("PRAGMA journal_size_limit = %d;", config.JournalSizeLimit))
-		if err != nil {
+	if len(config.Require) > 0 {
+		if err := requireCapabilities(context.Background(), db, config.Require); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	if config.StrictPragmas {
+		if err := verifyPragmas(context.Background(), db, config); err != nil {
+			_ = db.Close()
 			return nil, err
 		}
 	}
 
-	return db, err
+	if config.Migrations != nil {
+		if err := applyMigrations(db, config.Migrations); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+func applyMigrations(db *sql.DB, fsys fs.FS) error {
+	migrator, err := migrate.New(fsys)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(context.Background(), db)
+	return err
 }
@@ -1,19 +1,14 @@
 package sqlite
 
 import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
 	"testing"
-)
-
-func Test_buildDSN_EmptyParams(t *testing.T) {
-	t.Parallel()
+	"testing/fstest"
 
-	dsn := buildDSN(":memory", []string{})
-
-	expected := ":memory"
-	if dsn != expected {
-		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
-	}
-}
+	"github.com/DATA-DOG/go-sqlmock"
+)
 
 func Test_buildMattnDSN(t *testing.T) {
 	t.Parallel()
@@ -25,10 +20,11 @@ func Test_buildMattnDSN(t *testing.T) {
 	c.ForeignKey = true
 	c.DeferForeignKeys = true
 	c.JournalMode = JournalPersist
+	c.QueryOnly = true
 	c.SyncMode = SyncExtra
 
 	dsn := buildMattnDSN(c)
-	expected := "?_auto_vacuum=2&_timeout=100&_case_sensitive_like=true&_fk=true&defer_foreign_keys=true&_journal=PERSIST&_sync=3"
+	expected := "?_auto_vacuum=2&_case_sensitive_like=true&_defer_foreign_keys=true&_fk=true&_journal=PERSIST&_query_only=true&_sync=3&_timeout=100"
 	if dsn != expected {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
@@ -42,7 +38,7 @@ func Test_buildMattnDSN_DeferForeignKeysJustWithForeignKey(t *testing.T) {
 	c.DeferForeignKeys = true
 
 	dsn := buildMattnDSN(c)
-	expected := "?_timeout=4000&_journal=WAL&_sync=1"
+	expected := "?_journal=WAL&_sync=1&_timeout=4000"
 	if dsn != expected {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
@@ -55,7 +51,7 @@ func Test_buildMattnDSN_InvalidVacuumMode(t *testing.T) {
 	c.AutoVacuumMode = "invalid"
 
 	dsn := buildMattnDSN(c)
-	expected := "?_timeout=4000&_fk=true&_journal=WAL&_sync=1"
+	expected := "?_fk=true&_journal=WAL&_sync=1&_timeout=4000"
 	if dsn != expected {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
@@ -68,7 +64,7 @@ func Test_buildMattnDSN_InvalidSyncMode(t *testing.T) {
 	c.SyncMode = "invalid"
 
 	dsn := buildMattnDSN(c)
-	expected := "?_timeout=4000&_fk=true&_journal=WAL"
+	expected := "?_fk=true&_journal=WAL&_timeout=4000"
 	if dsn != expected {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
@@ -84,10 +80,11 @@ func Test_buildModerncDSN(t *testing.T) {
 	c.ForeignKey = true
 	c.DeferForeignKeys = true
 	c.JournalMode = JournalPersist
+	c.QueryOnly = true
 	c.SyncMode = SyncExtra
 
 	dsn := buildModerncDSN(c)
-	expected := "?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(100)&_pragma=case_sensitive_like(1)&_pragma=foreign_keys(1)&_pragma=defer_foreign_keys(1)&_pragma=journal_mode(PERSIST)&_pragma=synchronous(EXTRA)"
+	expected := "?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(100)&_pragma=case_sensitive_like(1)&_pragma=foreign_keys(1)&_pragma=defer_foreign_keys(1)&_pragma=journal_mode(PERSIST)&_pragma=query_only(1)&_pragma=synchronous(EXTRA)"
 	if dsn != expected {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
@@ -132,3 +129,174 @@ func Test_buildModerncDSN_InvalidSyncMode(t *testing.T) {
 		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
 	}
 }
+
+func extendedPragmasConfig() *Config {
+	c := newConfig()
+	c.CacheSize = -2000
+	c.CellSizeCheck = true
+	c.LockingMode = LockingExclusive
+	c.MmapSize = 268435456
+	c.PageSize = 8192
+	c.RecursiveTriggers = true
+	c.SecureDelete = SecureDeleteFast
+	c.TempStore = TempStoreMemory
+	c.WALAutoCheckpoint = 500
+	return c
+}
+
+func Test_buildMattnDSN_ExtendedPragmas(t *testing.T) {
+	t.Parallel()
+
+	dsn := buildMattnDSN(extendedPragmasConfig())
+	expected := "?_cache_size=-2000&_fk=true&_locking_mode=EXCLUSIVE&_recursive_triggers=true&_secure_delete=FAST&_sync=1&_timeout=4000"
+	if dsn != expected {
+		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
+	}
+}
+
+func Test_buildModerncDSN_ExtendedPragmas(t *testing.T) {
+	t.Parallel()
+
+	dsn := buildModerncDSN(extendedPragmasConfig())
+	expected := "?_pragma=busy_timeout(4000)&_pragma=cache_size(-2000)&_pragma=cell_size_check(1)&_pragma=foreign_keys(1)&_pragma=locking_mode(EXCLUSIVE)&_pragma=mmap_size(268435456)&_pragma=recursive_triggers(1)&_pragma=secure_delete(FAST)&_pragma=synchronous(NORMAL)&_pragma=temp_store(MEMORY)&_pragma=wal_autocheckpoint(500)"
+	if dsn != expected {
+		t.Fatalf("expected dsn '%s', got '%s'", expected, dsn)
+	}
+}
+
+func Test_buildDriverDSN_TxLock(t *testing.T) {
+	t.Parallel()
+
+	c := newConfig()
+	c.TxLock = TxImmediate
+
+	c.Driver = DriverMattn
+	expected := "?_fk=true&_journal=WAL&_sync=1&_timeout=4000&_txlock=immediate"
+	if dsn := buildDriverDSN(c); dsn != expected {
+		t.Errorf("expected dsn '%s', got '%s'", expected, dsn)
+	}
+
+	c.Driver = DriverModernc
+	expected = "?_pragma=busy_timeout(4000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate"
+	if dsn := buildDriverDSN(c); dsn != expected {
+		t.Errorf("expected dsn '%s', got '%s'", expected, dsn)
+	}
+}
+
+func Test_connPragmas(t *testing.T) {
+	t.Parallel()
+
+	c := extendedPragmasConfig()
+	c.Driver = DriverMattn
+	expected := []string{
+		"PRAGMA page_size = 8192;",
+		"PRAGMA journal_mode = WAL;",
+		"PRAGMA journal_size_limit = 100000000;",
+		"PRAGMA cell_size_check = 1;",
+		"PRAGMA mmap_size = 268435456;",
+		"PRAGMA temp_store = MEMORY;",
+		"PRAGMA wal_autocheckpoint = 500;",
+	}
+	if got := connPragmas(c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+
+	c.Driver = DriverModernc
+	expected = []string{
+		"PRAGMA page_size = 8192;",
+		"PRAGMA journal_mode = WAL;",
+		"PRAGMA journal_size_limit = 100000000;",
+	}
+	if got := connPragmas(c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, got)
+	}
+}
+
+func Test_connect_WithMigrations(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("PRAGMA user_version;").
+		WillReturnRows(sqlmock.NewRows([]string{"user_version"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("PRAGMA user_version = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithMigrations(fstest.MapFS{
+			"1_create_users.sql": {Data: []byte("CREATE TABLE users (id INTEGER);")},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_connect_WithConnectorWrapper(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var wrapped []string
+	wrapper := func(name string) ConnectorWrapper {
+		return func(config *Config, c driver.Connector) driver.Connector {
+			wrapped = append(wrapped, name+" "+string(config.Driver))
+			return c
+		}
+	}
+	db, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithConnectorWrapper(wrapper("inner")),
+		WithConnectorWrapper(wrapper("outer")),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+
+	expected := []string{"inner " + string(DriverModernc), "outer " + string(DriverModernc)}
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, wrapped)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_connect_WithMigrationsError(t *testing.T) {
+	t.Parallel()
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("PRAGMA user_version;").WillReturnError(errUnitTest)
+	mock.ExpectClose()
+
+	_, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithMigrations(fstest.MapFS{
+			"1_create_users.sql": {Data: []byte("CREATE TABLE users (id INTEGER);")},
+		}),
+	)
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
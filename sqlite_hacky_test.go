@@ -30,12 +30,16 @@ func (u unitTestDriver) Open(name string) (driver.Conn, error) {
 	return nil, nil
 }
 
-//go:linkname unregisterAllDrivers database/sql.unregisterAllDrivers
-func unregisterAllDrivers()
-
 //go:linkname drivers database/sql.drivers
 var drivers map[string]driver.Driver
 
+// unregisterAllDrivers removes all drivers registered per [sql.Register].
+func unregisterAllDrivers() {
+	for name := range drivers {
+		delete(drivers, name)
+	}
+}
+
 func TestDependsOnGlobalRegisterGroup(t *testing.T) {
 	sqlMockDriver := drivers["sqlmock"]
 
@@ -60,7 +64,9 @@ func TestDependsOnGlobalRegisterGroup(t *testing.T) {
 					sql.Register(tc.driverName, unitTestDriver{})
 				}
 
-				got, err := detectDriver()
+				config := newConfig()
+				err := selectDriver(config)
+				got := config.Driver
 				if err != nil && tc.wantErr == nil {
 					t.Fatalf("did not expect error '%s'", err)
 				}
@@ -108,7 +114,7 @@ func TestDependsOnGlobalRegisterGroup(t *testing.T) {
 	})
 
 	t.Run("Test_connect_WithoutRegisteredDrivers", func(t *testing.T) {
-		_, err := connect(sql.Open)
+		_, err := connect(openConnector)
 		if !errors.Is(err, ErrCantDetectDriver) {
 			t.Fatalf("expect error to be '%s', got '%s'", ErrCantDetectDriver, err)
 		}
@@ -120,7 +126,7 @@ func TestDependsOnGlobalRegisterGroup(t *testing.T) {
 		defer unregisterAllDrivers()
 
 		_, err := connect(
-			func(_, _ string) (*sql.DB, error) {
+			func(_, _ string) (driver.Connector, error) {
 				return nil, errUnitTest
 			},
 			WithDriverName(driverName),
@@ -141,7 +147,7 @@ func(_, _ string) (*sql.DB, error) {
 		defer unregisterAllDrivers()
 
 		_, err := connect(
-			sql.Open,
+			openConnector,
 			WithDriverName(driverName),
 			WithDriver(DriverModernc),
 		)
@@ -154,7 +160,8 @@ func(_, _ string) (*sql.DB, error) {
 	drivers["sqlmock"] = sqlMockDriver
 
 	t.Run("Test_connect_ErrorWithPing", func(t *testing.T) {
-		db, mock, err := sqlmock.New(
+		db, mock, err := sqlmock.NewWithDSN(
+			t.Name(),
 			sqlmock.MonitorPingsOption(true),
 		)
 		if err != nil {
@@ -164,11 +171,12 @@ func(_, _ string) (*sql.DB, error) {
 		mock.ExpectPing().WillReturnError(errUnitTest)
 
 		_, err = connect(
-			func(_, _ string) (*sql.DB, error) {
-				return db, nil
+			func(_, _ string) (driver.Connector, error) {
+				return dsnConnector{driver: db.Driver(), dsn: t.Name()}, nil
 			},
 			WithDriverName("sqlmock"),
 			WithDriver(DriverModernc),
+			WithJournalSizeLimit(0),
 		)
 		if !errors.Is(err, errUnitTest) {
 			t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
@@ -176,16 +184,12 @@ func(_, _ string) (*sql.DB, error) {
 	})
 
 	t.Run("Test_connect_LimitConnections", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
-		if err != nil {
-			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-		}
-		defer db.Close()
+		connector, mock := buildMockConnector(t, t.Name())
 		mock.ExpectExec("PRAGMA .*").WillReturnResult(sqlmock.NewResult(1, 1))
 
-		db, err = connect(
-			func(_, _ string) (*sql.DB, error) {
-				return db, nil
+		db, err := connect(
+			func(_, _ string) (driver.Connector, error) {
+				return connector, nil
 			},
 			WithDriverName("sqlmock"),
 			WithDriver(DriverModernc),
@@ -214,16 +218,12 @@ func(_, _ string) (*sql.DB, error) {
 	})
 
 	t.Run("Test_connect_JournalSizeLimitError", func(t *testing.T) {
-		db, mock, err := sqlmock.New()
-		if err != nil {
-			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-		}
-		defer db.Close()
+		connector, mock := buildMockConnector(t, t.Name())
 		mock.ExpectExec("PRAGMA .*").WillReturnError(errUnitTest)
 
-		_, err = connect(
-			func(_, _ string) (*sql.DB, error) {
-				return db, nil
+		_, err := connect(
+			func(_, _ string) (driver.Connector, error) {
+				return connector, nil
 			},
 			WithDriverName("sqlmock"),
 			WithDriver(DriverModernc),
@@ -234,18 +234,18 @@ func(_, _ string) (*sql.DB, error) {
 	})
 
 	t.Run("Test_Connect", func(t *testing.T) {
-		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		db, mock, err := sqlmock.NewWithDSN(t.Name(), sqlmock.MonitorPingsOption(true))
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
 		defer db.Close()
-		mock.ExpectPing()
 		mock.ExpectExec("PRAGMA").
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectPing()
 
 		oldOpenFunc := openFunc
-		openFunc = func(_, _ string) (*sql.DB, error) {
-			return db, nil
+		openFunc = func(_, _ string) (driver.Connector, error) {
+			return dsnConnector{driver: db.Driver(), dsn: t.Name()}, nil
 		}
 		defer func() {
 			openFunc = oldOpenFunc
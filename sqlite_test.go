@@ -67,6 +67,62 @@ func TestShutdown(t *testing.T) {
 	}
 }
 
+func TestShutdown_WithCheckpoint(t *testing.T) {
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("PRAGMA optimize;").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(0, 0, 0))
+	mock.ExpectClose()
+
+	if err := sqlite.Shutdown(db, sqlite.WithShutdownCheckpoint()); err != nil {
+		t.Fatalf("did not expected error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestShutdown_WithAnalysisLimit(t *testing.T) {
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`PRAGMA analysis_limit = 100;`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("PRAGMA optimize;").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectClose()
+
+	if err := sqlite.Shutdown(db, sqlite.WithShutdownAnalysisLimit(100)); err != nil {
+		t.Fatalf("did not expected error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestShutdown_WithErrorInCheckpoint(t *testing.T) {
+	db, mock := buildMockDB(t)
+	defer db.Close()
+
+	errUnitTest := errors.New("unittest")
+
+	mock.ExpectExec("PRAGMA optimize;").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnError(errUnitTest)
+
+	err := sqlite.Shutdown(db, sqlite.WithShutdownCheckpoint())
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestShutdown_WithErrorInOptimize(t *testing.T) {
 	db, mock := buildMockDB(t)
 	defer db.Close()
@@ -0,0 +1,179 @@
+package sqliteotel
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+var (
+	_ sqlite.Hook   = &hook{}
+	_ sqlite.TxHook = &hook{}
+
+	_ driver.Connector = &connector{}
+	_ io.Closer        = &connector{}
+)
+
+// spanKey is the context key of the span started by the hook, other hooks may start spans as well.
+type spanKey struct{}
+
+// hook starts a span per query and transaction of a database and records its instruments.
+//
+// The attributes are set by init with the config of the first connector, all connectors of a
+// [sqlite.ConnectPool] share the database path and driver.
+type hook struct {
+	tracer             trace.Tracer
+	busyRetries        metric.Int64Counter
+	checkpointDuration metric.Float64Histogram
+
+	once        sync.Once
+	attrs       []attribute.KeyValue
+	metricAttrs metric.MeasurementOption
+}
+
+func newHook(c *config) *hook {
+	meter := c.meterProvider.Meter(ScopeName)
+	busyRetries, err := meter.Int64Counter("db.sqlite.busy_retries",
+		metric.WithDescription("The retries of transactions, which failed with SQLITE_BUSY or SQLITE_LOCKED."),
+		metric.WithUnit("{retry}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	checkpointDuration, err := meter.Float64Histogram("db.sqlite.checkpoint.duration",
+		metric.WithDescription("The duration of the WAL checkpoints."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &hook{
+		tracer:             c.tracerProvider.Tracer(ScopeName),
+		busyRetries:        busyRetries,
+		checkpointDuration: checkpointDuration,
+	}
+}
+
+// init sets the attributes of the database file and driver, it's called before the first connection.
+func (h *hook) init(c *config, config *sqlite.Config, file string) {
+	h.once.Do(func() {
+		h.attrs = []attribute.KeyValue{dbSystem, KeyDriver.String(string(config.Driver))}
+		if file != "" {
+			h.attrs = append(h.attrs, KeyDBName.String(file))
+		}
+		h.attrs = append(h.attrs, c.attrs...)
+		h.metricAttrs = metric.WithAttributes(h.attrs...)
+	})
+}
+
+// start starts the span name for query.
+func (h *hook) start(ctx context.Context, name, query string) context.Context {
+	attrs := h.attrs
+	if query != "" {
+		attrs = append(append(make([]attribute.KeyValue, 0, len(attrs)+1), attrs...), KeyDBStatement.String(query))
+	}
+	ctx, span := h.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// Before starts the span of query.
+func (h *hook) Before(ctx context.Context, query string, _ []any) context.Context {
+	return h.start(ctx, "sqlite.query", query)
+}
+
+// After ends the span of query and records the duration of a checkpoint.
+func (h *hook) After(ctx context.Context, query string, _ []any, dur time.Duration, err error) {
+	if h.checkpointDuration != nil && isCheckpoint(query) && !errors.Is(err, driver.ErrSkip) {
+		h.checkpointDuration.Record(ctx, dur.Seconds(), h.metricAttrs)
+	}
+	end(ctx, err)
+}
+
+// BeforeTx starts the span of the transaction, which ends with its commit or rollback.
+func (h *hook) BeforeTx(ctx context.Context) context.Context {
+	attempt := sqlite.TxAttempt(ctx)
+	if attempt > 0 && h.busyRetries != nil {
+		h.busyRetries.Add(ctx, 1, h.metricAttrs)
+	}
+	ctx = h.start(ctx, "sqlite.tx", "")
+	if attempt > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(KeyTxAttempt.Int(attempt))
+	}
+	return ctx
+}
+
+// AfterTx ends the span of the transaction.
+func (h *hook) AfterTx(ctx context.Context, op sqlite.TxOp, err error) {
+	if span, ok := ctx.Value(spanKey{}).(trace.Span); ok && op != sqlite.TxOpBegin {
+		span.AddEvent(string(op))
+	}
+	end(ctx, err)
+}
+
+// end ends the span of ctx with the status of err.
+func end(ctx context.Context, err error) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func isCheckpoint(query string) bool {
+	return strings.Contains(strings.ToLower(query), "wal_checkpoint")
+}
+
+// connector unregisters the WAL size of a database on close.
+type connector struct {
+	driver.Connector
+	walSize metric.Registration
+}
+
+// Close unregisters the WAL size and closes the wrapped connector, it's called by [sql.DB.Close].
+func (c *connector) Close() error {
+	err := c.walSize.Unregister()
+	if closer, ok := c.Connector.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// registerWALSize observes the size of the WAL file of the database file.
+func registerWALSize(meter metric.Meter, file string, attrs metric.MeasurementOption) (metric.Registration, error) {
+	walSize, err := meter.Int64ObservableGauge("db.sqlite.wal.size",
+		metric.WithDescription("The size of the WAL file."),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var size int64
+		info, err := os.Stat(file + "-wal")
+		switch {
+		case err == nil:
+			size = info.Size()
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+		o.ObserveInt64(walSize, size, attrs)
+		return nil
+	}, walSize)
+}
@@ -0,0 +1,155 @@
+// Package sqliteotel instruments the databases of "github.com/lanz-dev/go-sqlite" with OpenTelemetry.
+//
+// [sqliteotel.Instrument] adds a [sqlite.Hook] to the databases of [sqlite.Connect] and [sqlite.ConnectPool]:
+//   - a span sqlite.query per statement and sqlite.tx per transaction with the attributes db.system, db.statement,
+//     db.name (the database file) and db.sqlite.driver
+//   - the counter db.sqlite.busy_retries of the retries of [sqlite.WithTx]
+//   - the histogram db.sqlite.checkpoint.duration of all `PRAGMA wal_checkpoint` statements,
+//     e.g. of [sqlite.StartMaintenance]
+//   - the gauge db.sqlite.wal.size of the size of the WAL file
+//
+// [sqliteotel.RegisterPoolMetrics] adds the pool wait time of a [sql.DB]:
+//
+//	db, err := sqlite.Connect(sqlite.WithPath("file:data.db"), sqliteotel.Instrument())
+//	if err != nil {
+//		return err
+//	}
+//	reg, err := sqliteotel.RegisterPoolMetrics(db)
+//
+// The spans of the queries within a transaction are children of the context passed to them,
+// not of the span of the transaction. The BEGIN, COMMIT and ROLLBACK statements of a [sqlite.TxMode]
+// have spans as well.
+// The arguments of the queries are never recorded.
+package sqliteotel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// ScopeName is the name of the instrumentation scope of all spans and metrics.
+const ScopeName = "github.com/lanz-dev/go-sqlite/sqliteotel"
+
+// The keys of the attributes.
+const (
+	KeyDBSystem    = attribute.Key("db.system")
+	KeyDBStatement = attribute.Key("db.statement")
+	KeyDBName      = attribute.Key("db.name")
+	KeyDriver      = attribute.Key("db.sqlite.driver")
+	KeyTxAttempt   = attribute.Key("db.sqlite.tx.attempt")
+)
+
+var dbSystem = KeyDBSystem.String("sqlite")
+
+// Option is a func to configure the instrumentation.
+type Option func(c *config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attrs          []attribute.KeyValue
+}
+
+func buildConfig(opts []Option) *config {
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithTracerProvider will set the provider of the tracer, the global provider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider will set the provider of the meter, the global provider is used by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithAttributes will add attrs to all spans and metrics.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+// Instrument returns a [sqlite.Option], which adds a [sqlite.Hook] with spans and metrics to the database.
+//
+// Errors creating the instruments are passed to [otel.Handle].
+func Instrument(opts ...Option) sqlite.Option {
+	c := buildConfig(opts)
+	return func(config *sqlite.Config) {
+		h := newHook(c)
+		sqlite.WithQueryHook(h)(config)
+		sqlite.WithConnectorWrapper(func(config *sqlite.Config, dc driver.Connector) driver.Connector {
+			return wrapConnector(c, config, h, dc)
+		})(config)
+	}
+}
+
+// wrapConnector initializes h with config and wraps dc to unregister the WAL size on close.
+func wrapConnector(c *config, config *sqlite.Config, h *hook, dc driver.Connector) driver.Connector {
+	file, err := sqlite.DatabaseFile(config.Path)
+	if err != nil {
+		otel.Handle(err)
+	}
+	h.init(c, config, file)
+	if file == "" {
+		return dc
+	}
+
+	walSize, err := registerWALSize(c.meterProvider.Meter(ScopeName), file, h.metricAttrs)
+	if err != nil {
+		otel.Handle(err)
+		return dc
+	}
+	return &connector{Connector: dc, walSize: walSize}
+}
+
+// RegisterPoolMetrics registers the pool metrics of db:
+//   - the counter db.client.connections.wait_time of the seconds waited for a connection
+//   - the counter db.client.connections.wait_count of the connections waited for
+//
+// Call Unregister of the returned [metric.Registration] before db is closed.
+func RegisterPoolMetrics(db *sql.DB, opts ...Option) (metric.Registration, error) {
+	c := buildConfig(opts)
+	meter := c.meterProvider.Meter(ScopeName)
+
+	waitTime, err := meter.Float64ObservableCounter("db.client.connections.wait_time",
+		metric.WithDescription("The time waited for a connection of the pool."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	waitCount, err := meter.Int64ObservableCounter("db.client.connections.wait_count",
+		metric.WithDescription("The connections waited for."),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := metric.WithAttributes(append([]attribute.KeyValue{dbSystem}, c.attrs...)...)
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := db.Stats()
+		o.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), attrs)
+		o.ObserveInt64(waitCount, stats.WaitCount, attrs)
+		return nil
+	}, waitTime, waitCount)
+}
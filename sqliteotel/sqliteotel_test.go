@@ -0,0 +1,229 @@
+package sqliteotel_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	_ "modernc.org/sqlite"
+
+	"github.com/lanz-dev/go-sqlite"
+	"github.com/lanz-dev/go-sqlite/sqliteotel"
+)
+
+// connect opens an instrumented database in a temp dir, which records to the returned exporter and reader.
+func connect(t *testing.T, opts ...sqlite.Option) (*sql.DB, string, *tracetest.InMemoryExporter, *sdkmetric.ManualReader, *sdkmetric.MeterProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	path := "file:" + filepath.Join(t.TempDir(), "data.db")
+	db, err := sqlite.Connect(append([]sqlite.Option{
+		sqlite.WithPath(path),
+		sqlite.WithDriver(sqlite.DriverModernc),
+		sqliteotel.Instrument(
+			sqliteotel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+			sqliteotel.WithMeterProvider(meterProvider),
+			sqliteotel.WithAttributes(attribute.String("service", "test")),
+		),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db, path, exporter, reader, meterProvider
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("did not expect error '%s' for '%s'", err, query)
+	}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestInstrument_Spans(t *testing.T) {
+	t.Parallel()
+
+	db, path, exporter, _, _ := connect(t)
+	file, err := sqlite.DatabaseFile(path)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	mustExec(t, db, "CREATE TABLE t (id INTEGER PRIMARY KEY);")
+
+	stmt, err := db.Prepare("INSERT INTO t VALUES (?);")
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := stmt.Exec(1); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	_ = stmt.Close()
+
+	err = sqlite.WithTx(context.Background(), db, sqlite.TxOptions{Mode: sqlite.TxImmediate}, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM t;")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := db.Query("SELECT * FROM missing;"); err == nil {
+		t.Fatal("expected an error for a missing table")
+	}
+
+	type span struct {
+		name, statement string
+		failed          bool
+	}
+	expected := []span{
+		{"sqlite.query", "CREATE TABLE t (id INTEGER PRIMARY KEY);", false},
+		{"sqlite.query", "INSERT INTO t VALUES (?);", false},
+		{"sqlite.query", "BEGIN IMMEDIATE;", false},
+		{"sqlite.query", "DELETE FROM t;", false},
+		{"sqlite.query", "COMMIT;", false},
+		{"sqlite.tx", "", false},
+		{"sqlite.query", "SELECT * FROM missing;", true},
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != len(expected) {
+		t.Fatalf("expected '%d' spans, got '%v'", len(expected), spans.Snapshots())
+	}
+	for i, s := range spans {
+		got := span{s.Name, attr(s.Attributes, sqliteotel.KeyDBStatement).AsString(), s.Status.Code == codes.Error}
+		if got != expected[i] {
+			t.Errorf("expected '%v', got '%v'", expected[i], got)
+		}
+		if v := attr(s.Attributes, sqliteotel.KeyDBSystem).AsString(); v != "sqlite" {
+			t.Errorf("expected '%s', got '%s'", "sqlite", v)
+		}
+		if v := attr(s.Attributes, sqliteotel.KeyDBName).AsString(); v != file {
+			t.Errorf("expected '%s', got '%s'", file, v)
+		}
+		if v := attr(s.Attributes, sqliteotel.KeyDriver).AsString(); v != string(sqlite.DriverModernc) {
+			t.Errorf("expected '%s', got '%s'", sqlite.DriverModernc, v)
+		}
+		if v := attr(s.Attributes, "service").AsString(); v != "test" {
+			t.Errorf("expected '%s', got '%s'", "test", v)
+		}
+	}
+}
+
+func TestInstrument_Metrics(t *testing.T) {
+	t.Parallel()
+
+	db, _, _, reader, meterProvider := connect(t, sqlite.WithDisabledLimits(), sqlite.WithBusyTimeout(1))
+	mustExec(t, db, "CREATE TABLE t (id INTEGER PRIMARY KEY);")
+
+	ctx := context.Background()
+	lock, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	released := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := lock.ExecContext(ctx, "ROLLBACK;")
+		_ = lock.Close()
+		released <- err
+	}()
+
+	err = sqlite.WithTx(ctx, db, sqlite.TxOptions{Mode: sqlite.TxImmediate, MaxRetries: 10, Backoff: 10 * time.Millisecond},
+		func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO t VALUES (1);")
+			return err
+		})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := <-released; err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	mustExec(t, db, "PRAGMA wal_checkpoint(TRUNCATE);")
+
+	reg, err := sqliteotel.RegisterPoolMetrics(db, sqliteotel.WithMeterProvider(meterProvider))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer reg.Unregister() //nolint:errcheck
+
+	metrics := collect(t, reader)
+
+	retries, ok := metrics["db.sqlite.busy_retries"].(metricdata.Sum[int64])
+	if !ok || len(retries.DataPoints) != 1 || retries.DataPoints[0].Value < 1 {
+		t.Errorf("expected at least one busy retry, got '%v'", metrics["db.sqlite.busy_retries"])
+	}
+	checkpoints, ok := metrics["db.sqlite.checkpoint.duration"].(metricdata.Histogram[float64])
+	if !ok || len(checkpoints.DataPoints) != 1 || checkpoints.DataPoints[0].Count != 1 {
+		t.Errorf("expected one checkpoint, got '%v'", metrics["db.sqlite.checkpoint.duration"])
+	}
+	if _, ok := metrics["db.sqlite.wal.size"].(metricdata.Gauge[int64]); !ok {
+		t.Errorf("expected the WAL size, got '%v'", metrics["db.sqlite.wal.size"])
+	}
+	if _, ok := metrics["db.client.connections.wait_time"].(metricdata.Sum[float64]); !ok {
+		t.Errorf("expected the pool wait time, got '%v'", metrics["db.client.connections.wait_time"])
+	}
+	if _, ok := metrics["db.client.connections.wait_count"].(metricdata.Sum[int64]); !ok {
+		t.Errorf("expected the pool wait count, got '%v'", metrics["db.client.connections.wait_count"])
+	}
+}
+
+func TestInstrument_CloseUnregistersWALSize(t *testing.T) {
+	t.Parallel()
+
+	db, _, _, reader, _ := connect(t)
+	if err := db.Close(); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, ok := collect(t, reader)["db.sqlite.wal.size"]; ok {
+		t.Error("did not expect the WAL size of a closed database")
+	}
+}
+
+func TestInstrument_Backup(t *testing.T) {
+	t.Parallel()
+
+	db, _, _, _, _ := connect(t)
+	mustExec(t, db, "CREATE TABLE t (id INTEGER PRIMARY KEY);")
+	mustExec(t, db, "INSERT INTO t VALUES (1);")
+
+	dest := filepath.Join(t.TempDir(), "backup.db")
+	if err := sqlite.Backup(context.Background(), db, dest, nil); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+}
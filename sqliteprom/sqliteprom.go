@@ -0,0 +1,188 @@
+// Package sqliteprom exports the statistics of a database of "github.com/lanz-dev/go-sqlite" to Prometheus.
+//
+//	prometheus.MustRegister(sqliteprom.NewCollector(db, sqliteprom.WithConstLabels(prometheus.Labels{"db": "main"})))
+//
+// The metrics are read per [sqlite.Stats] on every scrape.
+package sqliteprom
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// DefaultTimeout of a scrape, see [sqliteprom.WithTimeout].
+const DefaultTimeout = 5 * time.Second
+
+var _ prometheus.Collector = &Collector{}
+
+// Option is a func to configure the [sqliteprom.Collector].
+type Option func(c *config)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	timeout     time.Duration
+}
+
+// WithNamespace will set the namespace of all metrics, "sqlite" by default.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels will add labels to all metrics, e.g. to tell multiple databases apart.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithTimeout will set the timeout of the queries of a scrape, [sqliteprom.DefaultTimeout] by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// Collector is a [prometheus.Collector] for the [sqlite.DBStats] of a database.
+type Collector struct {
+	db      *sql.DB
+	timeout time.Duration
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUse              *prometheus.Desc
+	idle               *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+	maxIdleClosed      *prometheus.Desc
+	maxIdleTimeClosed  *prometheus.Desc
+	maxLifetimeClosed  *prometheus.Desc
+
+	size                   *prometheus.Desc
+	freelistPages          *prometheus.Desc
+	walSize                *prometheus.Desc
+	journalMode            *prometheus.Desc
+	checkpointBusy         *prometheus.Desc
+	checkpointLogFrames    *prometheus.Desc
+	checkpointCheckpointed *prometheus.Desc
+	checkpointTimestamp    *prometheus.Desc
+	maintenanceRuns        *prometheus.Desc
+}
+
+// NewCollector returns the [sqliteprom.Collector] of db.
+func NewCollector(db *sql.DB, opts ...Option) *Collector {
+	cfg := &config{
+		namespace: "sqlite",
+		timeout:   DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(cfg.namespace, "", name), help, labels, cfg.constLabels)
+	}
+	return &Collector{
+		db:      db,
+		timeout: cfg.timeout,
+
+		maxOpenConnections: desc("max_open_connections", "Maximum number of open connections to the database."),
+		openConnections:    desc("open_connections", "The number of established connections both in use and idle."),
+		inUse:              desc("in_use_connections", "The number of connections currently in use."),
+		idle:               desc("idle_connections", "The number of idle connections."),
+		waitCount:          desc("wait_count_total", "The total number of connections waited for."),
+		waitDuration:       desc("wait_duration_seconds_total", "The total time blocked waiting for a new connection."),
+		maxIdleClosed:      desc("max_idle_closed_total", "The total number of connections closed due to SetMaxIdleConns."),
+		maxIdleTimeClosed:  desc("max_idle_time_closed_total", "The total number of connections closed due to SetConnMaxIdleTime."),
+		maxLifetimeClosed:  desc("max_lifetime_closed_total", "The total number of connections closed due to SetConnMaxLifetime."),
+
+		size:                   desc("size_bytes", "The size of the database, page_count * page_size."),
+		freelistPages:          desc("freelist_pages", "The number of unused pages of the database."),
+		walSize:                desc("wal_size_bytes", "The size of the WAL file on disk."),
+		journalMode:            desc("journal_mode", "The journal mode of the database, the value is always 1.", "mode"),
+		checkpointBusy:         desc("last_checkpoint_busy", "1 if the last checkpoint was blocked."),
+		checkpointLogFrames:    desc("last_checkpoint_log_frames", "The frames in the WAL file of the last checkpoint."),
+		checkpointCheckpointed: desc("last_checkpoint_checkpointed_frames", "The frames checkpointed by the last checkpoint."),
+		checkpointTimestamp:    desc("last_checkpoint_timestamp_seconds", "The time of the last checkpoint, 0 if there was none."),
+		maintenanceRuns:        desc("maintenance_runs_total", "The total number of successful maintenance runs.", "task"),
+	}
+}
+
+// Describe implements [prometheus.Collector].
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.descs() {
+		ch <- d
+	}
+}
+
+func (c *Collector) descs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.maxOpenConnections, c.openConnections, c.inUse, c.idle, c.waitCount, c.waitDuration,
+		c.maxIdleClosed, c.maxIdleTimeClosed, c.maxLifetimeClosed,
+		c.size, c.freelistPages, c.walSize, c.journalMode,
+		c.checkpointBusy, c.checkpointLogFrames, c.checkpointCheckpointed, c.checkpointTimestamp,
+		c.maintenanceRuns,
+	}
+}
+
+// Collect implements [prometheus.Collector].
+//
+// If the statistics can't be read, an invalid metric with the error is reported for the size.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	stats, err := sqlite.Stats(ctx, c.db)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.size, err)
+		return
+	}
+
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...)
+	}
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+
+	pool := stats.Pool
+	gauge(c.maxOpenConnections, float64(pool.MaxOpenConnections))
+	gauge(c.openConnections, float64(pool.OpenConnections))
+	gauge(c.inUse, float64(pool.InUse))
+	gauge(c.idle, float64(pool.Idle))
+	counter(c.waitCount, float64(pool.WaitCount))
+	counter(c.waitDuration, pool.WaitDuration.Seconds())
+	counter(c.maxIdleClosed, float64(pool.MaxIdleClosed))
+	counter(c.maxIdleTimeClosed, float64(pool.MaxIdleTimeClosed))
+	counter(c.maxLifetimeClosed, float64(pool.MaxLifetimeClosed))
+
+	gauge(c.size, float64(stats.Size()))
+	gauge(c.freelistPages, float64(stats.FreelistCount))
+	gauge(c.walSize, float64(stats.WALSize))
+	gauge(c.journalMode, 1, strings.ToLower(string(stats.JournalMode)))
+
+	var busy, timestamp float64
+	if stats.LastCheckpoint.Busy {
+		busy = 1
+	}
+	if !stats.LastCheckpointTime.IsZero() {
+		timestamp = float64(stats.LastCheckpointTime.UnixNano()) / float64(time.Second)
+	}
+	gauge(c.checkpointBusy, busy)
+	gauge(c.checkpointLogFrames, float64(stats.LastCheckpoint.LogFrames))
+	gauge(c.checkpointCheckpointed, float64(stats.LastCheckpoint.Checkpointed))
+	gauge(c.checkpointTimestamp, timestamp)
+
+	counter(c.maintenanceRuns, float64(stats.Optimizes), string(sqlite.MaintenanceOptimize))
+	counter(c.maintenanceRuns, float64(stats.Vacuums), "vacuum")
+	counter(c.maintenanceRuns, float64(stats.IncrementalVacuums), string(sqlite.MaintenanceIncrementalVacuum))
+	counter(c.maintenanceRuns, float64(stats.Checkpoints), string(sqlite.MaintenanceCheckpoint))
+}
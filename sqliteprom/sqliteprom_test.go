@@ -0,0 +1,95 @@
+package sqliteprom_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	_ "modernc.org/sqlite"
+
+	"github.com/lanz-dev/go-sqlite"
+	"github.com/lanz-dev/go-sqlite/sqliteprom"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	db, err := sqlite.Connect(
+		sqlite.WithDriver(sqlite.DriverModernc),
+		sqlite.WithPath("file:"+filepath.Join(t.TempDir(), "data.db")),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (v TEXT);"); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := sqlite.OptimizeContext(context.Background(), db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	collector := sqliteprom.NewCollector(db, sqliteprom.WithConstLabels(prometheus.Labels{"db": "test"}))
+	expected := `
+# HELP sqlite_journal_mode The journal mode of the database, the value is always 1.
+# TYPE sqlite_journal_mode gauge
+sqlite_journal_mode{db="test",mode="wal"} 1
+# HELP sqlite_maintenance_runs_total The total number of successful maintenance runs.
+# TYPE sqlite_maintenance_runs_total counter
+sqlite_maintenance_runs_total{db="test",task="checkpoint"} 0
+sqlite_maintenance_runs_total{db="test",task="incremental_vacuum"} 0
+sqlite_maintenance_runs_total{db="test",task="optimize"} 1
+sqlite_maintenance_runs_total{db="test",task="vacuum"} 0
+# HELP sqlite_max_open_connections Maximum number of open connections to the database.
+# TYPE sqlite_max_open_connections gauge
+sqlite_max_open_connections{db="test"} 1
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"sqlite_journal_mode", "sqlite_maintenance_runs_total", "sqlite_max_open_connections")
+	if err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(collector); got != 21 {
+		t.Errorf("expected '%d', got '%d'", 21, got)
+	}
+	if got := testutil.ToFloat64(filtered{collector, "sqlite_wal_size_bytes"}); got <= 0 {
+		t.Errorf("expected a WAL size, got '%v'", got)
+	}
+}
+
+func TestCollector_Error(t *testing.T) {
+	t.Parallel()
+
+	db, err := sqlite.Connect(sqlite.WithDriver(sqlite.DriverModernc))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	_ = db.Close()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(sqliteprom.NewCollector(db, sqliteprom.WithNamespace("app")))
+	if _, err := registry.Gather(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Errorf("expected the error of the closed database, got '%v'", err)
+	}
+}
+
+// collect passes the metrics of collector with the name to ch.
+// filtered collects only the metric name of the wrapped collector.
+type filtered struct {
+	prometheus.Collector
+	name string
+}
+
+func (f filtered) Collect(ch chan<- prometheus.Metric) {
+	all := make(chan prometheus.Metric, 100)
+	f.Collector.Collect(all)
+	close(all)
+	for m := range all {
+		if strings.Contains(m.Desc().String(), `"`+f.name+`"`) {
+			ch <- m
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package sqlitetest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var errUnsupportedFixture = errors.New("unsupported fixture, expected a .sql, .csv or .json file")
+
+// LoadFixtures loads all files of fsys in lexical order into db within a single transaction.
+//
+// The table of a CSV or JSON file is named after the file without extension and without a numeric prefix,
+// so "1_users.csv" and "users.json" are loaded into the table "users":
+//   - "*.sql" files are executed as is
+//   - "*.csv" files need a header with the column names, empty values are inserted as NULL
+//   - "*.json" files contain an array of objects with the column names as keys,
+//     nested objects and arrays are inserted as JSON text
+//
+// Foreign keys are checked on commit, so the order of the files doesn't matter.
+// The test fails, if a fixture can't be loaded.
+func LoadFixtures(t testing.TB, db *sql.DB, fsys fs.FS) {
+	t.Helper()
+
+	if err := loadFixtures(context.Background(), db, fsys); err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' loading fixtures", err)
+	}
+}
+
+func loadFixtures(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON;"); err != nil {
+		return err
+	}
+
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := loadFixture(ctx, tx, fsys, name); err != nil {
+			return fmt.Errorf("fixture '%s': %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func loadFixture(ctx context.Context, tx *sql.Tx, fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	switch path.Ext(name) {
+	case ".sql":
+		_, err := tx.ExecContext(ctx, string(data))
+		return err
+	case ".csv":
+		columns, rows, err := parseCSV(data)
+		if err != nil {
+			return err
+		}
+		return insertRows(ctx, tx, fixtureTable(name), columns, rows)
+	case ".json":
+		columns, rows, err := parseJSON(data)
+		if err != nil {
+			return err
+		}
+		return insertRows(ctx, tx, fixtureTable(name), columns, rows)
+	}
+	return errUnsupportedFixture
+}
+
+// fixtureTable returns the table of the fixture name, "1_users.csv" is loaded into "users".
+func fixtureTable(name string) string {
+	table := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if prefix, rest, ok := strings.Cut(table, "_"); ok && prefix != "" && strings.Trim(prefix, "0123456789") == "" {
+		return rest
+	}
+	return table
+}
+
+func parseCSV(data []byte) ([]string, [][]any, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	rows := make([][]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make([]any, len(record))
+		for i, v := range record {
+			if v != "" {
+				row[i] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return records[0], rows, nil
+}
+
+func parseJSON(data []byte) ([]string, [][]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, nil, err
+	}
+
+	keys := map[string]bool{}
+	for _, object := range objects {
+		for key := range object {
+			keys[key] = true
+		}
+	}
+	columns := make([]string, 0, len(keys))
+	for key := range keys {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+
+	rows := make([][]any, 0, len(objects))
+	for _, object := range objects {
+		row := make([]any, len(columns))
+		for i, column := range columns {
+			v, err := jsonValue(object[column])
+			if err != nil {
+				return nil, nil, err
+			}
+			row[i] = v
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
+
+func jsonValue(v any) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return v, nil
+}
+
+func insertRows(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]any) error {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdent(column)
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s);",
+		quoteIdent(table),
+		strings.Join(quoted, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "),
+	)
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
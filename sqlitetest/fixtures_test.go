@@ -0,0 +1,86 @@
+package sqlitetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_fixtureTable(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"users.csv":        "users",
+		"1_users.csv":      "users",
+		"010_users.json":   "users",
+		"dir/2_orders.csv": "orders",
+		"user_roles.csv":   "user_roles",
+		"_users.csv":       "_users",
+	}
+	for name, expected := range tests {
+		if got := fixtureTable(name); got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+}
+
+func Test_parseCSV(t *testing.T) {
+	t.Parallel()
+
+	columns, rows, err := parseCSV([]byte("id,name\n1,Alice\n2,\n"))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"id", "name"}) {
+		t.Errorf("unexpected columns '%v'", columns)
+	}
+	expected := [][]any{{"1", "Alice"}, {"2", nil}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, rows)
+	}
+}
+
+func Test_parseJSON(t *testing.T) {
+	t.Parallel()
+
+	columns, rows, err := parseJSON([]byte(`[{"id": 1, "score": 1.5, "tags": ["a"]}, {"id": 2, "active": true}]`))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"active", "id", "score", "tags"}) {
+		t.Errorf("unexpected columns '%v'", columns)
+	}
+	expected := [][]any{
+		{nil, int64(1), 1.5, `["a"]`},
+		{true, int64(2), nil, nil},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, rows)
+	}
+
+	if _, _, err := parseJSON([]byte(`{"id": 1}`)); err == nil {
+		t.Error("expected an error for an object instead of an array")
+	}
+}
+
+func Test_formatValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, ""},
+		{[]byte("blob"), "blob"},
+		{"text", "text"},
+		{int64(42), "42"},
+		{1.5, "1.5"},
+		{true, "1"},
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "2024-01-02T03:04:05Z"},
+	}
+	for _, tc := range tests {
+		if got := formatValue(tc.value); got != tc.want {
+			t.Errorf("expected '%s', got '%s'", tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package sqlitetest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("sqlitetest.update", false, "write the golden files of sqlitetest.Golden")
+
+// Golden compares the content of table with the golden file path.
+//
+// The golden file is a CSV file with a header, so it can be loaded per [sqlitetest.LoadFixtures] as well.
+// The rows are sorted by all columns and NULL is written as empty value.
+//
+// Run the tests with the flag -sqlitetest.update to write the golden files.
+func Golden(t testing.TB, db *sql.DB, table, path string) {
+	t.Helper()
+
+	got, err := dumpTable(context.Background(), db, table)
+	if err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' reading the table '%s'", err, table)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("sqlitetest: did not expect error '%s'", err)
+		}
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatalf("sqlitetest: did not expect error '%s'", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s', run with -sqlitetest.update to write the golden file", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("sqlitetest: table '%s' differs from the golden file '%s'\n--- got\n%s--- want\n%s", table, path, got, want)
+	}
+}
+
+// dumpTable returns the rows of table as CSV.
+func dumpTable(ctx context.Context, db *sql.DB, table string) ([]byte, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0;", quoteIdent(table)))
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	_ = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]string, len(columns))
+	for i := range columns {
+		order[i] = strconv.Itoa(i + 1)
+	}
+	rows, err = db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT * FROM %s ORDER BY %s;", quoteIdent(table), strings.Join(order, ", "),
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(columns); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			record[i] = formatValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// formatValue formats v independent of the driver.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
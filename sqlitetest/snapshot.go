@@ -0,0 +1,38 @@
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+// DBSnapshot is a copy of a database taken per [sqlitetest.Snapshot].
+type DBSnapshot struct {
+	db   *sql.DB
+	path string
+}
+
+// Snapshot copies db per [sqlite.Backup] into a temp file of t.
+//
+// Use [sqlitetest.DBSnapshot.Restore] to reset db between subtests.
+func Snapshot(t testing.TB, db *sql.DB) *DBSnapshot {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := sqlite.Backup(context.Background(), db, path, nil); err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' taking a snapshot", err)
+	}
+	return &DBSnapshot{db: db, path: path}
+}
+
+// Restore replaces the content of the database with the snapshot per [sqlite.Restore].
+func (s *DBSnapshot) Restore(t testing.TB) {
+	t.Helper()
+
+	if err := sqlite.Restore(context.Background(), s.db, s.path, nil); err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' restoring a snapshot", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Package sqlitetest provides helpers for tests using "github.com/lanz-dev/go-sqlite".
+//
+// Like [sqlite.Connect] it doesn't import a driver, so the tests have to import one.
+package sqlitetest
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lanz-dev/go-sqlite"
+)
+
+var dbCount atomic.Int64
+
+// New returns a database in a temp file of t with the defaults of [sqlite.Connect], which is closed on the cleanup of t.
+//
+// The test fails, if the database can't be opened.
+func New(t testing.TB, opts ...sqlite.Option) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "data.db")
+	db, err := sqlite.Connect(append([]sqlite.Option{sqlite.WithURI(sqlite.FilePath(path))}, opts...)...)
+	if err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' for the database '%s'", err, path)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("sqlitetest: did not expect error '%s' closing the database '%s'", err, path)
+		}
+	})
+	return db
+}
+
+// NewDB returns a named in-memory database per [sqlite.ConnectMemory], which is dropped on the cleanup of t.
+//
+// Every call gets its own database, named after the test. All connections of the returned [sql.DB] and
+// all goroutines using it see the same database. The test fails, if the database can't be opened.
+func NewDB(t testing.TB, opts ...sqlite.Option) *sql.DB {
+	t.Helper()
+
+	name := fmt.Sprintf("%s_%d", t.Name(), dbCount.Add(1))
+	db, drop, err := sqlite.ConnectMemory(name, opts...)
+	if err != nil {
+		t.Fatalf("sqlitetest: did not expect error '%s' for the database '%s'", err, name)
+	}
+	t.Cleanup(func() {
+		if err := drop(); err != nil {
+			t.Errorf("sqlitetest: did not expect error '%s' dropping the database '%s'", err, name)
+		}
+	})
+	return db
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DBStats are the statistics of a database, see [sqlite.Stats].
+type DBStats struct {
+	Pool sql.DBStats // Statistics of the connection pool
+
+	File          string      // File of the main database, "" for an in-memory database
+	PageCount     int64       // https://www.sqlite.org/pragma.html#pragma_page_count
+	PageSize      int64       // https://www.sqlite.org/pragma.html#pragma_page_size
+	FreelistCount int64       // https://www.sqlite.org/pragma.html#pragma_freelist_count
+	JournalMode   JournalMode // https://www.sqlite.org/pragma.html#pragma_journal_mode
+	WALSize       int64       // Size of the WAL file on disk, 0 if there is none
+
+	LastCheckpoint     CheckpointResult // Result of the last [sqlite.Checkpoint]
+	LastCheckpointTime time.Time        // Time of the last checkpoint, zero if there was none
+
+	Optimizes          int64 // Successful runs of [sqlite.OptimizeContext]
+	Vacuums            int64 // Successful runs of [sqlite.VacuumContext]
+	IncrementalVacuums int64 // Successful runs of the incremental vacuum of the maintenance
+	Checkpoints        int64 // Successful runs of [sqlite.Checkpoint], e.g. of the maintenance
+}
+
+// Size returns the size of the database in bytes, page_count * page_size.
+func (s *DBStats) Size() int64 {
+	return s.PageCount * s.PageSize
+}
+
+// Stats returns the statistics of db.
+//
+// The counts and the last checkpoint are only recorded for databases opened by [sqlite.Connect],
+// [sqlite.ConnectPool] or [sqlite.ConnectMemory].
+// The file sizes are derived from [sqlite.Config.Path] of these databases,
+// other databases use the file reported by `PRAGMA database_list`.
+func Stats(ctx context.Context, db *sql.DB) (*DBStats, error) {
+	stats := &DBStats{Pool: db.Stats()}
+
+	pragmas := []struct {
+		name string
+		dest any
+	}{
+		{"page_count", &stats.PageCount},
+		{"page_size", &stats.PageSize},
+		{"freelist_count", &stats.FreelistCount},
+		{"journal_mode", &stats.JournalMode},
+	}
+	for _, p := range pragmas {
+		if err := db.QueryRowContext(ctx, "PRAGMA "+p.name+";").Scan(p.dest); err != nil {
+			return nil, err
+		}
+	}
+	stats.JournalMode = JournalMode(strings.ToUpper(string(stats.JournalMode)))
+
+	m := lookupDBMetrics(db)
+	if m != nil {
+		m.fill(stats)
+	}
+
+	file, err := statsFile(ctx, db, m)
+	if err != nil {
+		return nil, err
+	}
+	stats.File = file
+	if file != "" {
+		info, err := os.Stat(file + "-wal")
+		switch {
+		case err == nil:
+			stats.WALSize = info.Size()
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// statsFile returns the file of the main database of db.
+func statsFile(ctx context.Context, db *sql.DB, m *dbMetrics) (string, error) {
+	if m != nil {
+		return DatabaseFile(m.path)
+	}
+
+	rows, err := db.QueryContext(ctx, "PRAGMA database_list;")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			seq        int
+			name, file string
+		)
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return "", err
+		}
+		if name == "main" {
+			return file, rows.Close()
+		}
+	}
+	return "", rows.Err()
+}
+
+// dbMetrics are the metrics recorded for a database opened by this package.
+type dbMetrics struct {
+	path string
+
+	optimizes          atomic.Int64
+	vacuums            atomic.Int64
+	incrementalVacuums atomic.Int64
+	checkpoints        atomic.Int64
+
+	mu                 sync.Mutex
+	lastCheckpoint     CheckpointResult
+	lastCheckpointTime time.Time
+}
+
+// lookupDBMetrics returns nil, if db wasn't opened by this package.
+func lookupDBMetrics(db *sql.DB) *dbMetrics {
+	if c := lookupConnector(db); c != nil {
+		return c.metrics
+	}
+	return nil
+}
+
+// countDBMetric increments the counter of db selected by field, if db was opened by this package.
+func countDBMetric(db *sql.DB, field func(m *dbMetrics) *atomic.Int64) {
+	if m := lookupDBMetrics(db); m != nil {
+		field(m).Add(1)
+	}
+}
+
+func (m *dbMetrics) recordCheckpoint(result CheckpointResult) {
+	m.checkpoints.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastCheckpoint = result
+	m.lastCheckpointTime = time.Now()
+}
+
+func (m *dbMetrics) fill(stats *DBStats) {
+	stats.Optimizes = m.optimizes.Load()
+	stats.Vacuums = m.vacuums.Load()
+	stats.IncrementalVacuums = m.incrementalVacuums.Load()
+	stats.Checkpoints = m.checkpoints.Load()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats.LastCheckpoint = m.lastCheckpoint
+	stats.LastCheckpointTime = m.lastCheckpointTime
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectStatsPragmas(mock sqlmock.Sqlmock, journalMode string) {
+	mock.ExpectQuery("PRAGMA page_count;").WillReturnRows(sqlmock.NewRows([]string{"page_count"}).AddRow(10))
+	mock.ExpectQuery("PRAGMA page_size;").WillReturnRows(sqlmock.NewRows([]string{"page_size"}).AddRow(4096))
+	mock.ExpectQuery("PRAGMA freelist_count;").WillReturnRows(sqlmock.NewRows([]string{"freelist_count"}).AddRow(2))
+	mock.ExpectQuery("PRAGMA journal_mode;").WillReturnRows(sqlmock.NewRows([]string{"journal_mode"}).AddRow(journalMode))
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "data.db")
+	if err := os.WriteFile(file+"-wal", []byte("wal"), 0o600); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	connector, mock := buildMockConnector(t, t.Name())
+	mock.ExpectExec("PRAGMA journal_size_limit").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("PRAGMA optimize;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("VACUUM;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`PRAGMA wal_checkpoint\(TRUNCATE\);`).
+		WillReturnRows(sqlmock.NewRows([]string{"busy", "log", "checkpointed"}).AddRow(1, 5, 3))
+	expectStatsPragmas(mock, "wal")
+
+	db, err := connect(
+		func(_, _ string) (driver.Connector, error) {
+			return connector, nil
+		},
+		WithDriver(DriverModernc),
+		WithURI(FilePath(file)),
+	)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := OptimizeContext(ctx, db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := VacuumContext(ctx, db); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if _, err := Checkpoint(ctx, db, CheckpointTruncate); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	stats, err := Stats(ctx, db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if stats.File != file || stats.WALSize != 3 || stats.Size() != 40960 || stats.FreelistCount != 2 {
+		t.Errorf("expected the file '%s' with 3 bytes WAL and 40960 bytes, got '%+v'", file, stats)
+	}
+	if stats.JournalMode != JournalWAL {
+		t.Errorf("expected '%s', got '%s'", JournalWAL, stats.JournalMode)
+	}
+	if stats.Optimizes != 1 || stats.Vacuums != 1 || stats.Checkpoints != 1 || stats.IncrementalVacuums != 0 {
+		t.Errorf("expected one optimize, vacuum and checkpoint, got '%+v'", stats)
+	}
+	expected := CheckpointResult{Busy: true, LogFrames: 5, Checkpointed: 3}
+	if stats.LastCheckpoint != expected || stats.LastCheckpointTime.IsZero() {
+		t.Errorf("expected '%+v', got '%+v' at '%s'", expected, stats.LastCheckpoint, stats.LastCheckpointTime)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+}
+
+func TestStats_DatabaseList(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	expectStatsPragmas(mock, "delete")
+	mock.ExpectQuery("PRAGMA database_list;").WillReturnRows(sqlmock.NewRows([]string{"seq", "name", "file"}).
+		AddRow(0, "main", "/missing/data.db").
+		AddRow(2, "other", "/missing/other.db"))
+
+	stats, err := Stats(context.Background(), db)
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if stats.File != "/missing/data.db" || stats.WALSize != 0 || stats.JournalMode != JournalDelete {
+		t.Errorf("expected the file '%s' without WAL, got '%+v'", "/missing/data.db", stats)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStats_Error(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("PRAGMA page_count;").WillReturnError(errUnitTest)
+
+	if _, err := Stats(context.Background(), db); !errors.Is(err, errUnitTest) {
+		t.Errorf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+}
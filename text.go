@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"encoding"
+	"fmt"
+	"strings"
+)
+
+// The modes implement [encoding.TextMarshaler] and [encoding.TextUnmarshaler], so they can be decoded from
+// config files, environment variables and flags. The decoding is case-insensitive and rejects unknown values.
+var (
+	_ encoding.TextUnmarshaler = new(AutoVacuumMode)
+	_ encoding.TextUnmarshaler = new(Driver)
+	_ encoding.TextUnmarshaler = new(JournalMode)
+	_ encoding.TextUnmarshaler = new(LockingMode)
+	_ encoding.TextUnmarshaler = new(SecureDelete)
+	_ encoding.TextUnmarshaler = new(SyncMode)
+	_ encoding.TextUnmarshaler = new(TempStore)
+	_ encoding.TextUnmarshaler = new(TxMode)
+)
+
+// unmarshalMode parses text per parse, an empty text is the default mode.
+func unmarshalMode[T ~string](text []byte, parse func(string) (T, error), errInvalid error) (T, error) {
+	var mode T
+	if len(text) == 0 {
+		return mode, nil
+	}
+	mode, err := parse(strings.TrimSpace(string(text)))
+	if err != nil {
+		return mode, fmt.Errorf("given '%s', %w", text, errInvalid)
+	}
+	return mode, nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s AutoVacuumMode) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], the numeric values of SQLite are accepted as well.
+func (s *AutoVacuumMode) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseAutoVacuumMode, ErrInvalidAutoVacuumMode)
+	if err != nil {
+		return err
+	}
+	*s = mode
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (d Driver) MarshalText() ([]byte, error) {
+	return []byte(d), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], the [sqlite.DriverAdapter] must be registered.
+func (d *Driver) UnmarshalText(text []byte) error {
+	driver := Driver(strings.TrimSpace(string(text)))
+	if _, ok := lookupDriverAdapter(driver); driver != "" && !ok {
+		return fmt.Errorf("given '%s', %w", text, ErrUnknownDriver)
+	}
+	*d = driver
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m JournalMode) MarshalText() ([]byte, error) {
+	return []byte(m), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *JournalMode) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseJournalMode, ErrInvalidJournalMode)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m LockingMode) MarshalText() ([]byte, error) {
+	return []byte(m), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *LockingMode) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseLockingMode, ErrInvalidLockingMode)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s SecureDelete) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], boolean values are accepted as well.
+func (s *SecureDelete) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseSecureDelete, ErrInvalidSecureDelete)
+	if err != nil {
+		return err
+	}
+	*s = mode
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s SyncMode) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], the numeric values of SQLite are accepted as well.
+func (s *SyncMode) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseSyncMode, ErrInvalidSyncMode)
+	if err != nil {
+		return err
+	}
+	*s = mode
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s TempStore) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], the numeric values of SQLite are accepted as well.
+func (s *TempStore) UnmarshalText(text []byte) error {
+	store, err := unmarshalMode(text, parseTempStore, ErrInvalidTempStore)
+	if err != nil {
+		return err
+	}
+	*s = store
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m TxMode) MarshalText() ([]byte, error) {
+	return []byte(m), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *TxMode) UnmarshalText(text []byte) error {
+	mode, err := unmarshalMode(text, parseTxMode, ErrInvalidTxMode)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
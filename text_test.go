@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJournalMode_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]JournalMode{
+		"":      JournalDefault,
+		"wal":   JournalWAL,
+		" WAL ": JournalWAL,
+		"off":   JournalOff,
+	}
+	for text, expected := range tests {
+		var got JournalMode
+		if err := got.UnmarshalText([]byte(text)); err != nil {
+			t.Fatalf("did not expect error '%s'", err)
+		}
+		if got != expected {
+			t.Errorf("expected '%s', got '%s'", expected, got)
+		}
+	}
+
+	mode := JournalWAL
+	if err := mode.UnmarshalText([]byte("WAL)&_pragma=query_only(1)")); !errors.Is(err, ErrInvalidJournalMode) {
+		t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidJournalMode, err)
+	}
+	if mode != JournalWAL {
+		t.Errorf("expected '%s', got '%s'", JournalWAL, mode)
+	}
+}
+
+func TestConfig_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	data := `{
+		"driver": "modernc.org/sqlite",
+		"path": "file:data.db",
+		"journal_mode": "delete",
+		"sync_mode": "2",
+		"auto_vacuum_mode": "incremental",
+		"busy_timeout": 100
+	}`
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+
+	got, err := buildConfig(WithConfig(config), WithCacheSize(-2000))
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	expected := "file:data.db?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(100)&_pragma=cache_size(-2000)&_pragma=foreign_keys(1)&_pragma=journal_mode(DELETE)&_pragma=synchronous(FULL)"
+	if got.DSN != expected {
+		t.Errorf("expected '%s', got '%s'", expected, got.DSN)
+	}
+}
+
+func TestConfig_UnmarshalJSON_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]error{
+		`{"journal_mode": "fast"}`:         ErrInvalidJournalMode,
+		`{"sync_mode": "sometimes"}`:       ErrInvalidSyncMode,
+		`{"auto_vacuum_mode": "always"}`:   ErrInvalidAutoVacuumMode,
+		`{"driver": "example.com/sqlite"}`: ErrUnknownDriver,
+		`{"tx_lock": "later"}`:             ErrInvalidTxMode,
+		`{"temp_store": "disk"}`:           ErrInvalidTempStore,
+		`{"locking_mode": "shared"}`:       ErrInvalidLockingMode,
+		`{"secure_delete": "slow"}`:        ErrInvalidSecureDelete,
+	}
+	for data, wantErr := range tests {
+		config := DefaultConfig()
+		if err := json.Unmarshal([]byte(data), &config); !errors.Is(err, wantErr) {
+			t.Errorf("expect error to be '%s', got '%s'", wantErr, err)
+		}
+	}
+}
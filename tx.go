@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// TxMode is the mode used to begin a transaction.
+//
+// See https://www.sqlite.org/lang_transaction.html.
+type TxMode string
+
+// The different available transaction modes for SQLite.
+//
+// See https://www.sqlite.org/lang_transaction.html.
+const (
+	TxDefault   TxMode = ""
+	TxDeferred  TxMode = "DEFERRED"
+	TxImmediate TxMode = "IMMEDIATE"
+	TxExclusive TxMode = "EXCLUSIVE"
+)
+
+// TxOptions for [sqlite.WithTx].
+type TxOptions struct {
+	Mode       TxMode        // Mode to begin the transaction, [sqlite.TxDefault] uses the mode of the connection
+	MaxRetries int           // Max retries if the transaction fails with SQLITE_BUSY or SQLITE_LOCKED
+	Backoff    time.Duration // Sleep before the first retry, doubled for every further retry
+}
+
+type (
+	txModeKey    struct{}
+	txAttemptKey struct{}
+)
+
+func withTxMode(ctx context.Context, mode TxMode) context.Context {
+	return context.WithValue(ctx, txModeKey{}, mode)
+}
+
+func txModeFromContext(ctx context.Context) TxMode {
+	mode, _ := ctx.Value(txModeKey{}).(TxMode)
+	return mode
+}
+
+// TxAttempt returns the attempt of [sqlite.WithTx] from the context passed to [sql.DB.BeginTx],
+// 0 for the first attempt and n for the n-th retry.
+//
+// It can be used by a [sqlite.ConnectorWrapper] to count the retries.
+func TxAttempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(txAttemptKey{}).(int)
+	return attempt
+}
+
+// WithTx will run fn within a transaction, which is committed if fn returns nil and rolled back otherwise.
+//
+// If the transaction fails with SQLITE_BUSY or SQLITE_LOCKED, it is retried up to opts.MaxRetries times.
+// So fn can be called multiple times and shouldn't have side effects outside the transaction.
+//
+// opts.Mode is applied to databases opened by [sqlite.Connect] or [sqlite.ConnectPool],
+// an unknown mode is rejected with [sqlite.ErrInvalidTxMode].
+// Other databases begin all transactions with the mode of their driver, see [sqlite.WithTxLock].
+// Use [sqlite.TxImmediate] for transactions which write, so a busy database is detected on begin
+// and not on the first write, where [sqlite.WithBusyTimeout] can't help.
+func WithTx(ctx context.Context, db *sql.DB, opts TxOptions, fn func(tx *sql.Tx) error) error {
+	if err := checkTxMode(opts.Mode); err != nil {
+		return err
+	}
+
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := runTx(context.WithValue(ctx, txAttemptKey{}, attempt), db, opts.Mode, fn)
+		if err == nil || attempt >= opts.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func runTx(ctx context.Context, db *sql.DB, mode TxMode, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(withTxMode(ctx, mode), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// modeTx is a transaction started with an explicit BEGIN statement.
+type modeTx struct {
+	conn *conn
+}
+
+func beginTxMode(ctx context.Context, c *conn, mode TxMode) (driver.Tx, error) {
+	var query string
+	switch mode {
+	case TxDeferred:
+		query = "BEGIN DEFERRED;"
+	case TxImmediate:
+		query = "BEGIN IMMEDIATE;"
+	case TxExclusive:
+		query = "BEGIN EXCLUSIVE;"
+	default:
+		return nil, fmt.Errorf("given '%s', %w", mode, ErrInvalidTxMode)
+	}
+	if _, err := c.ExecContext(ctx, query, nil); err != nil {
+		return nil, err
+	}
+	return &modeTx{conn: c}, nil
+}
+
+// checkTxMode accepts the known modes, so a mode can't end up in the BEGIN statement.
+func checkTxMode(mode TxMode) error {
+	switch mode {
+	case TxDefault, TxDeferred, TxImmediate, TxExclusive:
+		return nil
+	}
+	return fmt.Errorf("given '%s', %w", mode, ErrInvalidTxMode)
+}
+
+// Commit will commit the transaction and roll it back if the commit fails,
+// e.g. with SQLITE_BUSY the transaction would stay open.
+func (t *modeTx) Commit() error {
+	if _, err := t.conn.ExecContext(context.Background(), "COMMIT;", nil); err != nil {
+		_ = t.Rollback()
+		return err
+	}
+	return nil
+}
+
+func (t *modeTx) Rollback() error {
+	_, err := t.conn.ExecContext(context.Background(), "ROLLBACK;", nil)
+	return err
+}
@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func buildTxMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	connector, mock := buildMockConnector(t, t.Name())
+	db := sql.OpenDB(&initConnector{connector: connector})
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db, mock
+}
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO t").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("COMMIT;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := WithTx(context.Background(), db, TxOptions{Mode: TxImmediate}, func(tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT INTO t VALUES (1)")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_DefaultMode(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err := WithTx(context.Background(), db, TxOptions{}, func(tx *sql.Tx) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_Rollback(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN EXCLUSIVE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := WithTx(context.Background(), db, TxOptions{Mode: TxExclusive, MaxRetries: 3}, func(tx *sql.Tx) error {
+		return errUnitTest
+	})
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_InvalidMode(t *testing.T) {
+	tests := []TxMode{"IMMEDIATE; DROP TABLE t", "immediate ", "LATER"}
+	for _, mode := range tests {
+		mode := mode
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			db, mock := buildTxMock(t)
+			called := false
+			err := WithTx(context.Background(), db, TxOptions{Mode: mode}, func(tx *sql.Tx) error {
+				called = true
+				return nil
+			})
+			if !errors.Is(err, ErrInvalidTxMode) {
+				t.Fatalf("expect error to be '%s', got '%s'", ErrInvalidTxMode, err)
+			}
+			if called {
+				t.Error("did not expect fn to be called")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_beginTxMode_InvalidMode(t *testing.T) {
+	t.Parallel()
+
+	if _, err := beginTxMode(context.Background(), &conn{}, "IMMEDIATE; DROP TABLE t"); !errors.Is(err, ErrInvalidTxMode) {
+		t.Errorf("expect error to be '%s', got '%s'", ErrInvalidTxMode, err)
+	}
+}
+
+func TestWithTx_RollbackOnPanic(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	defer func() {
+		if p := recover(); p != errUnitTest {
+			t.Errorf("expected panic '%v', got '%v'", errUnitTest, p)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	}()
+	_ = WithTx(context.Background(), db, TxOptions{Mode: TxImmediate}, func(tx *sql.Tx) error {
+		panic(errUnitTest)
+	})
+}
+
+func TestWithTx_RetryOnBusy(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnError(codeError(CodeBusy))
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO t").WillReturnError(codeError(CodeLocked))
+	mock.ExpectExec("ROLLBACK;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO t").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("COMMIT;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var calls int
+	err := WithTx(context.Background(), db, TxOptions{
+		Mode:       TxImmediate,
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+	}, func(tx *sql.Tx) error {
+		calls++
+		_, err := tx.Exec("INSERT INTO t VALUES (1)")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected '%d', got '%d'", 2, calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTxAttempt(t *testing.T) {
+	t.Parallel()
+
+	var attempts []int
+	connector, mock := buildMockConnector(t, t.Name())
+	db := sql.OpenDB(&initConnector{connector: connector, hook: attemptHook(func(ctx context.Context) {
+		attempts = append(attempts, TxAttempt(ctx))
+	})})
+	defer db.Close()
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnError(codeError(CodeBusy))
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnError(codeError(CodeBusy))
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("COMMIT;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := WithTx(context.Background(), db, TxOptions{Mode: TxImmediate, MaxRetries: 2}, func(*sql.Tx) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("did not expect error '%s'", err)
+	}
+	if expected := []int{0, 1, 2, 0}; !reflect.DeepEqual(attempts, expected) {
+		t.Errorf("expected '%v', got '%v'", expected, attempts)
+	}
+	if got := TxAttempt(context.Background()); got != 0 {
+		t.Errorf("expected '%d', got '%d'", 0, got)
+	}
+}
+
+// attemptHook calls fn with the context of every query.
+type attemptHook func(ctx context.Context)
+
+func (h attemptHook) Before(ctx context.Context, _ string, _ []any) context.Context {
+	h(ctx)
+	return ctx
+}
+
+func (h attemptHook) After(context.Context, string, []any, time.Duration, error) {}
+
+func TestWithTx_MaxRetries(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnError(codeError(CodeBusy))
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnError(codeError(CodeBusy))
+
+	err := WithTx(context.Background(), db, TxOptions{Mode: TxImmediate, MaxRetries: 1}, func(tx *sql.Tx) error {
+		return nil
+	})
+	if !IsBusy(err) {
+		t.Fatalf("expected a busy error, got '%v'", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithTx_CommitError(t *testing.T) {
+	t.Parallel()
+
+	db, mock := buildTxMock(t)
+	mock.ExpectExec("BEGIN IMMEDIATE;").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("COMMIT;").WillReturnError(errUnitTest)
+	mock.ExpectExec("ROLLBACK;").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := WithTx(context.Background(), db, TxOptions{Mode: TxImmediate}, func(tx *sql.Tx) error {
+		return nil
+	})
+	if !errors.Is(err, errUnitTest) {
+		t.Fatalf("expect error to be '%s', got '%s'", errUnitTest, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// These errors will be returned by [sqlite.Connect] and [sqlite.ConnectPool] for an unknown value of a mode,
+// so an invalid value can't end up in the DSN.
+var (
+	ErrInvalidAutoVacuumMode = errors.New("invalid auto vacuum mode")
+	ErrInvalidJournalMode    = errors.New("invalid journal mode")
+	ErrInvalidLockingMode    = errors.New("invalid locking mode")
+	ErrInvalidSecureDelete   = errors.New("invalid secure delete mode")
+	ErrInvalidSyncMode       = errors.New("invalid sync mode")
+	ErrInvalidTempStore      = errors.New("invalid temp store")
+	ErrInvalidTxMode         = errors.New("invalid transaction mode")
+)
+
+// ErrInvalidOption will be returned for an option with a value out of range, like a negative busy timeout.
+var ErrInvalidOption = errors.New("invalid option value")
+
+// ErrIncompatibleConfig will be returned by [sqlite.Config.Validate] for options which can't be used together.
+var ErrIncompatibleConfig = errors.New("incompatible config")
+
+// Validate checks all modes and values of c, and the combinations of options which can't work.
+//
+// All found errors are joined. [sqlite.Connect] and [sqlite.ConnectPool] validate the config before connecting.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Path != "" {
+		errs = append(errs, validatePath(c.Path))
+	}
+	errs = append(errs,
+		validateModes(c),
+		checkBusyTimeout(c.BusyTimeout),
+		checkJournalSizeLimit(c.JournalSizeLimit),
+		checkMmapSize(c.MmapSize),
+		checkPageSize(c.PageSize),
+		checkReadConnections(c.ReadConnections),
+		checkRequirements(c.Require),
+	)
+
+	if c.DeferForeignKeys && !c.ForeignKey {
+		errs = append(errs, fmt.Errorf("deferred foreign keys without foreign key support, %w", ErrIncompatibleConfig))
+	}
+	if c.JournalMode == JournalWAL && isReadOnlyPath(c.Path) {
+		errs = append(errs, fmt.Errorf(
+			"journal mode '%s' with the read-only path '%s', %w", c.JournalMode, c.Path, ErrIncompatibleConfig,
+		))
+	}
+	if c.preset == PresetTest && c.Path != "" && !isMemoryPath(c.Path) {
+		errs = append(errs, fmt.Errorf("preset '%s' with the file path '%s', %w", c.preset, c.Path, ErrIncompatibleConfig))
+	}
+	if c.Migrations != nil && (c.QueryOnly || isReadOnlyPath(c.Path)) {
+		errs = append(errs, fmt.Errorf("migrations with a read-only connection, %w", ErrIncompatibleConfig))
+	}
+	return errors.Join(errs...)
+}
+
+// isReadOnlyPath reports whether path is a `file:` URI opened with [sqlite.OpenReadOnly] or as immutable.
+func isReadOnlyPath(path string) bool {
+	name, query, _ := strings.Cut(path, "?")
+	if !strings.HasPrefix(name, "file:") {
+		return false
+	}
+	values, err := url.ParseQuery(query)
+	return err == nil && (values.Get("mode") == string(OpenReadOnly) || values.Get("immutable") == "1")
+}
+
+// validateModes returns an error for the first unknown mode of config.
+func validateModes(config *Config) error {
+	switch config.AutoVacuumMode {
+	case AutoVacuumDefault, AutoVacuumNone, AutoVacuumFull, AutoVacuumIncremental:
+	default:
+		return fmt.Errorf("given '%s', %w", config.AutoVacuumMode, ErrInvalidAutoVacuumMode)
+	}
+	switch config.JournalMode {
+	case JournalDefault, JournalDelete, JournalTruncate, JournalPersist, JournalMemory, JournalWAL, JournalOff:
+	default:
+		return fmt.Errorf("given '%s', %w", config.JournalMode, ErrInvalidJournalMode)
+	}
+	switch config.LockingMode {
+	case LockingDefault, LockingNormal, LockingExclusive:
+	default:
+		return fmt.Errorf("given '%s', %w", config.LockingMode, ErrInvalidLockingMode)
+	}
+	switch config.SecureDelete {
+	case SecureDeleteDefault, SecureDeleteOff, SecureDeleteOn, SecureDeleteFast:
+	default:
+		return fmt.Errorf("given '%s', %w", config.SecureDelete, ErrInvalidSecureDelete)
+	}
+	switch config.SyncMode {
+	case SyncDefault, SyncOff, SyncNormal, SyncFull, SyncExtra:
+	default:
+		return fmt.Errorf("given '%s', %w", config.SyncMode, ErrInvalidSyncMode)
+	}
+	switch config.TempStore {
+	case TempStoreDefault, TempStoreFile, TempStoreMemory:
+	default:
+		return fmt.Errorf("given '%s', %w", config.TempStore, ErrInvalidTempStore)
+	}
+	return checkTxMode(config.TxLock)
+}
+
+func checkBusyTimeout(timeout int) error {
+	if timeout < 0 {
+		return fmt.Errorf("given busy timeout '%d', %w", timeout, ErrInvalidOption)
+	}
+	return nil
+}
+
+// checkJournalSizeLimit accepts -1, which is no limit for SQLite.
+func checkJournalSizeLimit(limit int) error {
+	if limit < -1 {
+		return fmt.Errorf("given journal size limit '%d', %w", limit, ErrInvalidOption)
+	}
+	return nil
+}
+
+func checkMmapSize(size int64) error {
+	if size < 0 {
+		return fmt.Errorf("given mmap size '%d', %w", size, ErrInvalidOption)
+	}
+	return nil
+}
+
+// checkPageSize accepts a power of two between 512 and 65536.
+func checkPageSize(size int) error {
+	if size != 0 && (size < 512 || size > 65536 || size&(size-1) != 0) {
+		return fmt.Errorf("given page size '%d', %w", size, ErrInvalidOption)
+	}
+	return nil
+}
+
+func checkReadConnections(n int) error {
+	if n < 0 {
+		return fmt.Errorf("given read connections '%d', %w", n, ErrInvalidOption)
+	}
+	return nil
+}
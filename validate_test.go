@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_buildConfig_InvalidModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     Option
+		wantErr error
+	}{
+		{"AutoVacuumMode", WithAutoVacuumMode("ALWAYS"), ErrInvalidAutoVacuumMode},
+		{"JournalMode", WithJournalMode("WAL)&_pragma=query_only(1)"), ErrInvalidJournalMode},
+		{"LockingMode", WithLockingMode("SHARED"), ErrInvalidLockingMode},
+		{"SecureDelete", WithSecureDelete("SLOW"), ErrInvalidSecureDelete},
+		{"SyncMode", WithSyncMode("normal"), ErrInvalidSyncMode},
+		{"TempStore", WithTempStore("DISK"), ErrInvalidTempStore},
+		{"TxLock", WithTxLock("LATER"), ErrInvalidTxMode},
+		{"Valid", WithJournalMode(JournalDelete), nil},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := buildConfig(WithDriver(DriverMattn), tc.opt)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_buildConfig_JoinsOptionErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildConfig(
+		WithDriver(DriverMattn),
+		WithBusyTimeout(-5),
+		WithPageSize(1000),
+		WithDSN("file:data.db?_fk=maybe"),
+	)
+	for _, wantErr := range []error{ErrInvalidOption, ErrInvalidDSN} {
+		if !errors.Is(err, wantErr) {
+			t.Errorf("expect error to be '%s', got '%s'", wantErr, err)
+		}
+	}
+	if got := len(err.(interface{ Unwrap() []error }).Unwrap()); got != 3 {
+		t.Errorf("expected '%d', got '%d'", 3, got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr error
+	}{
+		{"Defaults", func(*Config) {}, nil},
+		{"InvalidPath", func(c *Config) { c.Path = "file://host/data.db" }, ErrInvalidPath},
+		{"InvalidMode", func(c *Config) { c.SyncMode = "SOMETIMES" }, ErrInvalidSyncMode},
+		{"BusyTimeout", func(c *Config) { c.BusyTimeout = -1 }, ErrInvalidOption},
+		{"JournalSizeLimit", func(c *Config) { c.JournalSizeLimit = -2 }, ErrInvalidOption},
+		{"JournalSizeLimitUnlimited", func(c *Config) { c.JournalSizeLimit = -1 }, nil},
+		{"MmapSize", func(c *Config) { c.MmapSize = -1 }, ErrInvalidOption},
+		{"PageSize", func(c *Config) { c.PageSize = 4000 }, ErrInvalidOption},
+		{"PageSizeTooLarge", func(c *Config) { c.PageSize = 131072 }, ErrInvalidOption},
+		{"ReadConnections", func(c *Config) { c.ReadConnections = -1 }, ErrInvalidOption},
+		{"Require", func(c *Config) { c.Require = []string{"3.x"} }, ErrInvalidOption},
+		{"DeferForeignKeys", func(c *Config) { c.ForeignKey = false; c.DeferForeignKeys = true }, ErrIncompatibleConfig},
+		{"WALReadOnly", func(c *Config) { c.Path = "file:data.db?mode=ro" }, ErrIncompatibleConfig},
+		{"WALImmutable", func(c *Config) { c.Path = FilePath("data.db").Immutable().String() }, ErrIncompatibleConfig},
+		{"ReadOnly", func(c *Config) { c.Path = "file:data.db?mode=ro"; c.JournalMode = JournalDefault }, nil},
+		{"PresetTestFile", func(c *Config) { c.preset = PresetTest; c.Path = "data.db" }, ErrIncompatibleConfig},
+		{"PresetTestMemory", func(c *Config) { c.preset = PresetTest; c.Path = SharedMemory("test").String() }, nil},
+		{"MigrationsQueryOnly", func(c *Config) { c.QueryOnly = true; c.Migrations = fstest.MapFS{} }, ErrIncompatibleConfig},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := newConfig()
+			config.Path = "file:data.db"
+			tc.change(config)
+
+			err := config.Validate()
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("did not expect error '%s'", err)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expect error to be '%s', got '%s'", tc.wantErr, err)
+			}
+		})
+	}
+}